@@ -20,6 +20,7 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"time"
 
 	swr "github.com/gabereiser/swr"
@@ -33,9 +34,31 @@ under certain conditions; see LICENSE for details.
  `)
 
 	time.Sleep(1 * time.Second)
-	swr.Init()
 }
 
 func main() {
+	// `server validate` checks the world data and exits, non-zero if anything is wrong.
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		if swr.Validate() > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+	// `server export [area|all] [dir]` writes Graphviz and SVG maps of the world and exits.
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		name, dir := "all", "export"
+		if len(os.Args) > 2 {
+			name = os.Args[2]
+		}
+		if len(os.Args) > 3 {
+			dir = os.Args[3]
+		}
+		if err := swr.Export(name, dir); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+	swr.Init()
 	swr.Main()
 }
@@ -55,25 +55,17 @@ func do_password(entity Entity, args ...string) {
 func do_save(entity Entity, args ...string) {
 	if entity.IsPlayer() {
 		player := entity.(*PlayerProfile)
-		DB().SavePlayerData(player)
+		if err := DB().SavePlayerData(player); err != nil {
+			ErrorCheck(err)
+			entity.Send("\r\n&RUnable to save! Please tell an immortal.&d\r\n")
+			return
+		}
 		entity.Send("\r\n&YSaved. Ok.&d\r\n")
 	}
 }
 func do_look(entity Entity, args ...string) {
-	if entity_unspeakable_state(entity) {
-		entity.Send("\r\n&dYou are %s.&d\r\n", entity_unspeakable_reason(entity))
-		return
-	}
 	if entity.IsPlayer() {
-		ch := entity.GetCharData()
 		player := entity.(*PlayerProfile)
-		if ch.State == ENTITY_STATE_DEAD {
-			return
-		}
-		if ch.State == ENTITY_STATE_SLEEPING || ch.State == ENTITY_STATE_UNCONSCIOUS {
-			entity.Send("\r\n&cIn your dreams?...&d\r\n")
-			return
-		}
 		if len(args) == 0 { // l or look with no args
 			roomId := entity.RoomId()
 			shipId := entity.ShipId()
@@ -92,15 +84,21 @@ func do_look(entity Entity, args ...string) {
 							ANSI_TITLE_ALIGNMENT_CENTER)))
 				}
 				entity.Send(sprintf("&W%s&d\r\n\r\n", StitchParagraphs(telnet_encode(room.Desc), build_map(room))))
+				if room_is_outdoors(room) {
+					entity.Send("&c%s&d\r\n\r\n", room_get_weather(room).Look())
+				}
 				entity.Send("Exits: \r\n")
-				for dir, to_room_id := range room.Exits {
-					to_room := DB().GetRoom(to_room_id, shipId)
-					if k, ok := room.ExitFlags[dir]; ok {
-						exit_flags := k
-						ext := room_get_exit_status(exit_flags)
-						entity.Send(sprintf("&G%s&W - &Y[&W%s&Y] &C%s&d\r\n", capitalize(dir), to_room.Name, ext))
+				for _, exit := range room.GetExits() {
+					to_room := exit.GetTarget()
+					if to_room == nil || !room_exit_visible(entity, exit) {
+						continue
+					}
+					dir := exit.GetDirection()
+					if exit.IsDoor() {
+						ext := room_get_exit_status(exit)
+						entity.Send(sprintf("&G%s&W - &Y[&W%s&Y] &C%s&d\r\n", capitalize(dir), to_room.GetName(), ext))
 					} else {
-						entity.Send(sprintf("&G%s&W - &Y[&W%s&Y]&d\r\n", capitalize(dir), to_room.Name))
+						entity.Send(sprintf("&G%s&W - &Y[&W%s&Y]&d\r\n", capitalize(dir), to_room.GetName()))
 					}
 				}
 				entity.Send("\r\n")
@@ -108,7 +106,7 @@ func do_look(entity Entity, args ...string) {
 					for _, ship := range DB().ships {
 						s := ship.GetData()
 						if s.LocationId == roomId && !s.InSpace {
-							entity.Send("&b%-25s &w(&d%s&w)&d", s.Name, s.Type)
+							entity.Send("&b%-25s &w(&d%s&w)&d\r\n", s.Name, s.Type)
 						}
 					}
 					entity.Send("\r\n")
@@ -131,7 +129,15 @@ func do_look(entity Entity, args ...string) {
 					}
 				}
 				if shipId > 0 {
-					if ship.GetData().Cockpit == roomId || (ship.GetData().Ramp == roomId && !ship.GetData().InSpace) {
+					if ship.GetData().InSpace && ship_is_cockpit(ship.GetData(), roomId) {
+						entity.Send("\r\nThrough your ships viewscreen you see the stars of the &W%s&d system.\r\n", ship.GetData().CurrentSystem)
+						_, orbits := ship_landing_pads(ship.GetData())
+						for i, o := range orbits {
+							if i == 0 || orbits[i-1] != o {
+								entity.Send("&W%s&d fills the viewscreen, close enough to land on.\r\n", o)
+							}
+						}
+					} else if ship.GetData().Cockpit == roomId || (ship.GetData().Ramp == roomId && !ship.GetData().InSpace) {
 						room := DB().GetRoom(ship.GetData().LocationId, 0)
 						if room != nil {
 							entity.Send(fmt.Sprintf("\r\nThrough your ships viewscreen you see...\r\n\r\n%s\r\n",
@@ -139,14 +145,17 @@ func do_look(entity Entity, args ...string) {
 									ANSI_TITLE_STYLE_NORMAL,
 									ANSI_TITLE_ALIGNMENT_CENTER)))
 							entity.Send(sprintf("&W%s&d\r\n", StitchParagraphs(telnet_encode(room.Desc), build_map(room))))
-							for dir, to_room_id := range room.Exits {
-								to_room := DB().GetRoom(to_room_id, shipId)
-								if k, ok := room.ExitFlags[dir]; ok {
-									exit_flags := k
-									ext := room_get_exit_status(exit_flags)
-									entity.Send(sprintf("&G%s&W - &Y(&W%s&Y) &C%s&d\r\n", capitalize(dir), to_room.Name, ext))
+							for _, exit := range room.GetExits() {
+								to_room := exit.GetTarget()
+								if to_room == nil || !room_exit_visible(entity, exit) {
+									continue
+								}
+								dir := exit.GetDirection()
+								if exit.IsDoor() {
+									ext := room_get_exit_status(exit)
+									entity.Send(sprintf("&G%s&W - &Y(&W%s&Y) &C%s&d\r\n", capitalize(dir), to_room.GetName(), ext))
 								} else {
-									entity.Send(sprintf("&G%s&W - &Y(&W%s&Y)&d\r\n", capitalize(dir), to_room.Name))
+									entity.Send(sprintf("&G%s&W - &Y(&W%s&Y)&d\r\n", capitalize(dir), to_room.GetName()))
 								}
 							}
 							entity.Send("\r\n")
@@ -178,16 +187,11 @@ func do_look(entity Entity, args ...string) {
 
 		} else {
 			room := entity.GetRoom()
-			for _, e := range room.GetEntities() {
-				if e != entity {
-					ch := e.GetCharData()
-					for _, keyword := range ch.Keywords {
-						if strings.HasPrefix(strings.ToLower(keyword), strings.ToLower(args[0])) {
-							entity.Send("You look at %s and see...\r\n%s\r\n", ch.Name, ch.Desc)
-							return
-						}
-					}
-				}
+			target := target_entity(entity, room.GetEntities(), args[0])
+			if target != nil {
+				ch := target.GetCharData()
+				entity.Send("You look at %s and see...\r\n%s\r\n", ch.Name, ch.Desc)
+				return
 			}
 			item := room.FindItem(args[0])
 			if item != nil {
@@ -199,6 +203,10 @@ func do_look(entity Entity, args ...string) {
 				entity.Send("You look at %s and see...\r\n%s\r\n", item.GetData().Name, item.GetData().Desc)
 				return
 			}
+			if extra := extra_desc_look(entity, room, args[0]); extra != nil {
+				entity.Send("\r\n&W%s&d\r\n", telnet_encode(extra.Desc))
+				return
+			}
 		}
 	}
 	entity.Send("\r\n&dCan't find that here.\r\n")
@@ -236,45 +244,42 @@ func do_down(entity Entity, args ...string) {
 }
 
 func do_direction(entity Entity, direction string) {
-	if entity_unspeakable_state(entity) {
-		entity.Send("\r\n&dYou are %s.&d\r\n", entity_unspeakable_reason(entity))
-		return
-	}
-	if entity.GetCharData().State == ENTITY_STATE_SITTING {
-		entity.Send("\r\nYou are unable to move while sitting.\r\n")
-		return
-	}
-	if entity.IsFighting() {
-		entity.Send("\r\nYou are fighting!\r\n")
-		return
-	}
 	db := DB()
 	room := db.GetRoom(entity.RoomId(), entity.ShipId())
-	if !room.HasExit(direction) {
+	exit := room.GetExit(direction)
+	if exit == nil || !room_exit_visible(entity, exit) {
 		entity.Send("\r\nYou can't go that way.\r\n")
 		return
 	} else {
-		to_room := db.GetRoom(room.Exits[direction], entity.ShipId())
+		to_room := exit.GetTarget()
 		if to_room == nil {
 			entity.Send("\r\n&RThat room doesn't exist!\r\n")
 			return
 		} else {
-			locked := false
-			closed := false
-			flags := room.GetExitFlags(direction)
-			if flags != nil {
-				locked, closed = room_get_blocked_exit_flags(flags)
+			if exit.IsLocked() {
+				entity.Send("\r\nThe %s is locked.\r\n", exit.GetName())
+				return
 			}
-			if locked {
-				entity.Send("\r\nIt's locked.\r\n")
+			if exit.IsClosed() {
+				entity.Send("\r\nThe %s is closed.\r\n", exit.GetName())
 				return
 			}
-			if closed {
-				entity.Send("\r\nIt's closed.\r\n")
+			to := to_room.(*RoomData)
+			sector := room_get_sector(to)
+			if !entity_can_enter(entity, to) {
+				entity.Send("\r\n%s\r\n", sector.Refuse)
 				return
 			}
-			if entity.CurrentMv() > 0 {
-				entity.GetCharData().Mv[0]--
+			if !entity.IsPlayer() && to.IsFlagged(ROOM_FLAG_NO_MOB) {
+				return
+			}
+			if entity.IsPlayer() && room_is_full(to) && !entity_is_immortal(entity) {
+				entity.Send("\r\nThat room is private, and there's no space for you.\r\n")
+				return
+			}
+			if entity.CurrentMv() >= sector.Mv {
+				entity_stop_piloting(entity)
+				entity.GetCharData().Mv[0] -= sector.Mv
 				for _, e := range room.GetEntities() {
 					if entity_unspeakable_state(e) {
 						continue
@@ -287,7 +292,7 @@ func do_direction(entity Entity, direction string) {
 					}
 				}
 				go room_prog_exec(entity, "leave", direction)
-				entity.GetCharData().Room = to_room.Id
+				entity.GetCharData().Room = to_room.GetId()
 				do_look(entity)
 				go room_prog_exec(entity, "enter", direction_reverse(direction))
 				for _, e := range to_room.GetEntities() {
@@ -302,7 +307,7 @@ func do_direction(entity Entity, direction string) {
 					}
 				}
 			} else {
-				entity.Send("\r\n&You are too exhausted.\r\n")
+				entity.Send("\r\n&dYou are too exhausted.\r\n")
 				return
 			}
 		}
@@ -311,14 +316,6 @@ func do_direction(entity Entity, direction string) {
 
 func do_stand(entity Entity, args ...string) {
 	ch := entity.GetCharData()
-	if ch.State == ENTITY_STATE_DEAD {
-		entity.Send("\r\n&RYou can't move when you're dead.&d\r\n")
-		return
-	}
-	if ch.State == ENTITY_STATE_UNCONSCIOUS {
-		entity.Send("\r\n&YYou are unconscious...&d\r\n")
-		return
-	}
 	if ch.State == ENTITY_STATE_SITTING || ch.State == ENTITY_STATE_SLEEPING {
 		ch.State = ENTITY_STATE_NORMAL
 		entity.GetRoom().SendToOthers(entity, sprintf("\r\n&d%s stands up.\r\n", ch.Name))
@@ -332,13 +329,7 @@ func do_stand(entity Entity, args ...string) {
 
 func do_sit(entity Entity, args ...string) {
 	ch := entity.GetCharData()
-	if ch.State == ENTITY_STATE_DEAD {
-		entity.Send("\r\n&RYou can't move when you're dead.&d\r\n")
-		return
-	} else if ch.State == ENTITY_STATE_UNCONSCIOUS {
-		entity.Send("\r\n&YYou are unconscious...&d\r\n")
-		return
-	} else if ch.State == ENTITY_STATE_NORMAL {
+	if ch.State == ENTITY_STATE_NORMAL {
 		ch.State = ENTITY_STATE_SITTING
 		entity.GetRoom().SendToOthers(entity, sprintf("\r\n&d%s sits down.\r\n", ch.Name))
 		entity.Send("\r\n&dYou sit down.\r\n")
@@ -351,18 +342,10 @@ func do_sit(entity Entity, args ...string) {
 
 func do_sleep(entity Entity, args ...string) {
 	ch := entity.GetCharData()
-	if ch.State == ENTITY_STATE_DEAD {
-		entity.Send("\r\n&RYou're already permanently asleep (*DEAD*).&d\r\n")
-		return
-	}
 	if ch.State == ENTITY_STATE_SLEEPING {
 		entity.Send("\r\n&dYou're already asleep.\r\n")
 		return
 	}
-	if ch.State == ENTITY_STATE_UNCONSCIOUS {
-		entity.Send("\r\n&dYou're unconscious.\r\n")
-		return
-	}
 	if ch.State == ENTITY_STATE_FIGHTING {
 		entity.Send("\r\n&dYou can't sleep when you're fighting.\r\n")
 		return
@@ -377,14 +360,6 @@ func do_sleep(entity Entity, args ...string) {
 }
 
 func do_open(entity Entity, args ...string) {
-	if entity_unspeakable_state(entity) {
-		entity.Send("\r\n&dYou are %s.&d\r\n", entity_unspeakable_reason(entity))
-		return
-	}
-	if entity.GetCharData().State == ENTITY_STATE_SITTING {
-		entity.Send("\r\nYou are unable to move while sitting.\r\n")
-		return
-	}
 	db := DB()
 	room := db.GetRoom(entity.RoomId(), entity.ShipId())
 	if len(args) == 0 {
@@ -392,7 +367,7 @@ func do_open(entity Entity, args ...string) {
 		return
 	}
 	direction := get_direction_string(strings.ToLower(args[0]))
-	if !room.HasExit(direction) {
+	if exit := room.GetExit(direction); exit == nil || !room_exit_visible(entity, exit) {
 		entity.Send("\r\n&ROpen what?&d.\r\n")
 		return
 	}
@@ -400,14 +375,6 @@ func do_open(entity Entity, args ...string) {
 }
 
 func do_close(entity Entity, args ...string) {
-	if entity_unspeakable_state(entity) {
-		entity.Send("\r\n&dYou are %s.&d\r\n", entity_unspeakable_reason(entity))
-		return
-	}
-	if entity.GetCharData().State == ENTITY_STATE_SITTING {
-		entity.Send("\r\nYou are unable to move while sitting.\r\n")
-		return
-	}
 	db := DB()
 	room := db.GetRoom(entity.RoomId(), entity.ShipId())
 	if len(args) == 0 {
@@ -417,13 +384,129 @@ func do_close(entity Entity, args ...string) {
 	// TODO if args[0] is 'hatch' close the spaceship hatch/ramp.
 	// For now we'll assume it's a direction door.
 	direction := get_direction_string(strings.ToLower(args[0]))
-	if !room.HasExit(direction) {
+	if exit := room.GetExit(direction); exit == nil || !room_exit_visible(entity, exit) {
 		entity.Send("\r\n&RClose what?&d.\r\n")
 		return
 	}
 	room.CloseDoor(entity, direction, false)
 }
 
+func do_search(entity Entity, args ...string) {
+	ch := entity.GetCharData()
+	room := DB().GetRoom(entity.RoomId(), entity.ShipId())
+	if room == nil {
+		return
+	}
+	room.SendToOthers(entity, sprintf("\r\n%s searches around the room.\r\n", ch.Name))
+	found := room.SearchExits(entity)
+	if len(found) == 0 {
+		entity.Send("\r\nYou search around but find nothing.\r\n")
+		return
+	}
+	for _, exit := range found {
+		entity.Send("\r\n&YYou find a hidden %s leading %s!&d\r\n", exit.GetName(), exit.GetDirection())
+		room.SendToOthers(entity, sprintf("\r\n%s finds a hidden %s leading %s!\r\n", ch.Name, exit.GetName(), exit.GetDirection()))
+	}
+}
+
+func do_pick(entity Entity, args ...string) {
+	if len(args) == 0 {
+		entity.Send("\r\n&RPick which lock?&d\r\n")
+		return
+	}
+	room := DB().GetRoom(entity.RoomId(), entity.ShipId())
+	direction := get_direction_string(strings.ToLower(args[0]))
+	exit := room.GetExit(direction)
+	if exit == nil || !room_exit_visible(entity, exit) || !exit.IsDoor() {
+		entity.Send("\r\n&RThere's no door that way.&d\r\n")
+		return
+	}
+	room.PickDoor(entity, direction)
+}
+
+func do_track(entity Entity, args ...string) {
+	if len(args) == 0 {
+		entity.Send("\r\n&RTrack who?&d\r\n")
+		return
+	}
+	ch := entity.GetCharData()
+	room := DB().GetRoom(ch.Room, ch.Ship)
+	if room == nil {
+		return
+	}
+	skill := entity_get_skill_value(ch, "tracking")
+	if roll_dice("1d10") == 10 {
+		entity_add_skill_value(entity, "tracking", 1)
+	}
+	chance := 30 + skill
+	if chance > 95 {
+		chance = 95
+	}
+	// the better the tracker, the older and fainter the trail they can follow.
+	path := path_find(entity, room, func(r *RoomData) bool {
+		return target_entity(entity, r.GetEntities(), args[0]) != nil
+	}, 10+skill)
+	if path != nil && len(path) == 0 {
+		entity.Send("\r\n&dThey're right here!\r\n")
+		return
+	}
+	if path == nil || roll_dice("1d100") > chance {
+		entity.Send("\r\n&dYou can't find a trail.\r\n")
+		return
+	}
+	entity.Send("\r\n&YYou find a trail leading %s.&d\r\n", path[0])
+}
+
+func do_travel(entity Entity, args ...string) {
+	if len(args) == 0 {
+		entity.Send("\r\n&RTravel where?&d\r\nSyntax: travel <room name>\r\n")
+		return
+	}
+	ch := entity.GetCharData()
+	room := DB().GetRoom(ch.Room, ch.Ship)
+	if room == nil {
+		return
+	}
+	var path []string
+	if vnum, err := strconv.Atoi(args[0]); err == nil && len(args) == 1 {
+		path = path_find_room(entity, room, uint(vnum))
+	} else {
+		name := strings.ToLower(strings.Join(args, " "))
+		path = path_find(entity, room, func(r *RoomData) bool {
+			return strings.Contains(strings.ToLower(r.Name), name)
+		}, 0)
+	}
+	if path == nil {
+		entity.Send("\r\n&RYou don't know the way there.&d\r\n")
+		return
+	}
+	if len(path) == 0 {
+		entity.Send("\r\n&dYou're already there.\r\n")
+		return
+	}
+	entity.Send("\r\n&YYou set off, %s.&d\r\n", path_string(path))
+	travel_step(entity, path, room.Id)
+}
+
+// travel_step walks the entity along the path from room at a room a second, stopping if anything gets in the way.
+// Walking off somewhere else also stops it.
+func travel_step(entity Entity, path []string, at uint) {
+	ScheduleFunc(func() {
+		if DB().GetEntity(entity) == nil || entity.RoomId() != at {
+			return
+		}
+		if entity_position(entity) != POSITION_STANDING || !path_step(entity, path[0]) {
+			entity.Send("\r\n&RYou stop travelling.&d\r\n")
+			return
+		}
+		if len(path) == 1 {
+			entity.Send("\r\n&YYou have arrived.&d\r\n")
+			return
+		}
+		travel_step(entity, path[1:], entity.RoomId())
+	}, false, 1)
+}
+
 func do_get(entity Entity, args ...string) {
 	if len(args) == 0 {
 		entity.Send("\r\n&RGet what?&d\r\n")
@@ -432,21 +515,26 @@ func do_get(entity Entity, args ...string) {
 	db := DB()
 	ch := entity.GetCharData()
 	if len(args) == 1 {
-		// fetch an item from the room
+		// fetch an item (or all.items) from the room
 		room := db.GetRoom(ch.Room, ch.Ship)
-		item := room.FindItem(args[0])
-		if item == nil {
+		items := target_items(room.Items, args[0])
+		if len(items) == 0 {
 			entity.Send("\r\n&dCan't seem to find that.\r\n")
-		} else {
+			return
+		}
+		for _, item := range items {
+			if len(items) > 1 && item.IsCorpse() {
+				continue // get all shouldn't complain about every corpse in the room.
+			}
 			if !entity_pickup_item(entity, item) {
-				return
+				continue
 			}
 			room.RemoveItem(item)
 			room.SendToOthers(entity, sprintf("\r\n&P%s&d picks up &Y%s&d.\r\n", ch.Name, item.GetData().Name))
 			entity.Send("\r\n&dYou pick up &Y%s&d.\r\n", item.GetData().Name)
 			go room_prog_exec(entity, "get", item) // indiana jones...
-			return
 		}
+		return
 	}
 	// get <item> from
 	if len(args) == 2 {
@@ -457,7 +545,6 @@ func do_get(entity Entity, args ...string) {
 
 		from_container := args[2]
 		item_name := args[0]
-		ch := entity.GetCharData()
 		room := db.GetRoom(ch.Room, ch.Ship)
 		//on your person (backpack, bag)
 		item := entity.FindItem(from_container)
@@ -470,18 +557,19 @@ func do_get(entity Entity, args ...string) {
 			return
 		} else {
 			if item.IsContainer() || item.IsCorpse() {
-				i := item.GetData().FindItemInContainer(item_name)
-				if i == nil {
+				items := target_items(item.GetData().Items, item_name)
+				if len(items) == 0 {
 					entity.Send("\r\nCan't seem to find that in %s.\r\n", item.GetData().Name)
 					return
-				} else {
+				}
+				for _, i := range items {
 					if !entity_pickup_item(entity, i) {
-						return
+						continue
 					}
 					item.GetData().RemoveItem(i)
 					entity.Send("\r\n&dYou pick up &Y%s&d from &Y%s&d.\r\n", i.GetData().Name, item.GetData().Name)
-					return
 				}
+				return
 			}
 		}
 	}
@@ -516,24 +604,15 @@ func do_give(entity Entity, args ...string) {
 		}
 		quantity = q
 	}
-	var target Entity
-	for _, e := range entity.GetRoom().GetEntities() {
-		if e != entity {
-			for _, k := range e.GetCharData().Keywords {
-				if strings.HasPrefix(k, entity_name) {
-					target = e
-					break
-				}
-			}
-			if target != nil {
-				break
-			}
-		}
-	}
+	target := target_entity(entity, entity.GetRoom().GetEntities(), entity_name)
 	if target == nil {
 		entity.Send("\r\n&RUnable to find &W%s&R!&d\r\n", entity_name)
 		return
 	}
+	if target == entity {
+		entity.Send("\r\n&RYou can't give things to yourself.&d\r\n")
+		return
+	}
 	if item_name == "credits" {
 		uq := uint(quantity)
 		if entity.GetCharData().Gold < uq {
@@ -552,25 +631,29 @@ func do_give(entity Entity, args ...string) {
 		entity.Send("\r\n&YYou give &P%s&Y &w%d&Y credits.&d\r\n", target.GetCharData().Name, uq)
 		return
 	}
-	item := entity.FindItem(item_name)
-	if item == nil {
+	items := target_items(target_inventory(entity.GetCharData()), item_name)
+	if len(items) == 0 {
 		entity.Send("\r\n&RUnable to find &W%s&R!&d\r\n", item_name)
 		return
 	}
-	if item.GetData().Type == ITEM_TYPE_KEY {
-		entity.Send("\r\n&RUnable to find &W%s&R!&d\r\n", item_name) // ;)
-		return
-	}
-	if !entity_pickup_item(target, item) {
-		entity.Send("\r\n&RThey are unable to carry &W%s&R!&d\r\n", item_name)
-		return
-	}
-	entity.GetCharData().RemoveItem(item)
-	target.Send("\r\n&P%s&Y has given you &W%s&Y.&d\r\n", entity.GetCharData().Name, item.GetData().Name)
-	entity.Send("\r\n&YYou give &P%s&Y &W%s&Y.&d\r\n", target.GetCharData().Name, item.GetData().Name)
-	if !target.IsPlayer() {
-		if target.GetCharData().AI != nil {
-			target.GetCharData().AI.OnGive(entity, 1, item)
+	for _, item := range items {
+		if item.GetData().Type == ITEM_TYPE_KEY {
+			if len(items) == 1 {
+				entity.Send("\r\n&RUnable to find &W%s&R!&d\r\n", item_name) // ;)
+			}
+			continue
+		}
+		if !entity_pickup_item(target, item) {
+			entity.Send("\r\n&RThey are unable to carry &W%s&R!&d\r\n", item.GetData().Name)
+			continue
+		}
+		entity.GetCharData().RemoveItem(item)
+		target.Send("\r\n&P%s&Y has given you &W%s&Y.&d\r\n", entity.GetCharData().Name, item.GetData().Name)
+		entity.Send("\r\n&YYou give &P%s&Y &W%s&Y.&d\r\n", target.GetCharData().Name, item.GetData().Name)
+		if !target.IsPlayer() {
+			if target.GetCharData().AI != nil {
+				target.GetCharData().AI.OnGive(entity, 1, item)
+			}
 		}
 	}
 
@@ -585,11 +668,6 @@ func do_put(entity Entity, args ...string) {
 		container_name := args[2]
 		db := DB()
 		room := db.GetRoom(entity.RoomId(), entity.ShipId())
-		item := entity.FindItem(item_name)
-		if item == nil {
-			entity.Send("\r\n&RCan't seem to find that.&d\r\n")
-			return
-		}
 		container := entity.FindItem(container_name)
 		if container == nil {
 			container = room.FindItem(container_name)
@@ -598,7 +676,24 @@ func do_put(entity Entity, args ...string) {
 			entity.Send("\r\nCan't seem to find that container.\r\n")
 			return
 		}
-		container.GetData().AddItem(item)
+		if !container.IsContainer() {
+			entity.Send("\r\n&R%s is not a container.&d\r\n", capitalize(container.GetData().Name))
+			return
+		}
+		items := target_items(target_inventory(entity.GetCharData()), item_name)
+		if len(items) == 0 {
+			entity.Send("\r\n&RCan't seem to find that.&d\r\n")
+			return
+		}
+		for _, item := range items {
+			if item == container {
+				continue
+			}
+			entity.GetCharData().RemoveItem(item)
+			container.GetData().AddItem(item)
+			entity.Send("\r\n&YYou put &W%s&Y in &W%s&Y.&d\r\n", item.GetData().Name, container.GetData().Name)
+			room.SendToOthers(entity, sprintf("\r\n&P%s&d puts &Y%s&d in &Y%s&d.\r\n", entity.GetCharData().Name, item.GetData().Name, container.GetData().Name))
+		}
 
 	} else {
 		entity.Send("\r\n&CSyntax: put <item> in <container>.&d\r\n")
@@ -611,28 +706,31 @@ func do_drop(entity Entity, args ...string) {
 	}
 	db := DB()
 	item_name := args[0]
-	item := entity.FindItem(item_name)
-	if item == nil {
+	items := target_items(target_inventory(entity.GetCharData()), item_name)
+	if len(items) == 0 {
 		entity.Send("\r\nCan't find that in your inventory.\r\n")
 		return
 	}
 	room := db.GetRoom(entity.RoomId(), entity.ShipId())
-	room.AddItem(item)
-	entity.GetCharData().RemoveItem(item)
-	entity.Send("\r\n&YYou drop &W%s&Y.&d\r\n", item.GetData().Name)
 	ch := entity.GetCharData()
-	for _, e := range room.GetEntities() {
-		if e == nil {
-			continue
-		}
-		if e != entity {
-			e.Send("\r\n&P%s&d drops &Y%s&d.\r\n", ch.Name, item.GetData().Name)
-			if e.GetCharData().AI != nil {
-				e.GetCharData().AI.OnDrop(entity, item)
+	for _, item := range items {
+		item_set_decay(item)
+		room.AddItem(item)
+		ch.RemoveItem(item)
+		entity.Send("\r\n&YYou drop &W%s&Y.&d\r\n", item.GetData().Name)
+		for _, e := range room.GetEntities() {
+			if e == nil {
+				continue
+			}
+			if e != entity {
+				e.Send("\r\n&P%s&d drops &Y%s&d.\r\n", ch.Name, item.GetData().Name)
+				if e.GetCharData().AI != nil {
+					e.GetCharData().AI.OnDrop(entity, item)
+				}
 			}
 		}
+		go room_prog_exec(entity, "drop", item)
 	}
-	go room_prog_exec(entity, "drop", item)
 }
 
 func do_statsys(entity Entity, args ...string) {
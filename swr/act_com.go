@@ -31,10 +31,6 @@ func do_say(entity Entity, args ...string) {
 		return
 	}
 	speaker := entity.GetCharData()
-	if entity_unspeakable_state(entity) {
-		entity.Send("\r\n&dYou are %s.&d\r\n", entity_unspeakable_reason(entity))
-		return
-	}
 	if entity.IsPlayer() {
 		entity.Send("You say \"%s\"\n", words)
 	}
@@ -64,10 +60,6 @@ func do_shout(entity Entity, args ...string) {
 		entity.Send("\r\n&RShout what?&d\r\n")
 	}
 	speaker := entity.GetCharData()
-	if entity_unspeakable_state(entity) {
-		entity.Send("\r\n&dYou are %s.&d\r\n", entity_unspeakable_reason(entity))
-		return
-	}
 	if entity.IsPlayer() {
 		entity.Send("You shout \"%s\"!\n", words)
 	}
@@ -104,13 +96,13 @@ func yell(entity Entity, words string, roomId uint, dist uint, visited []uint) {
 			}
 		}
 		visited = append(visited, roomId)
-		for d, e := range room.Exits {
-			if exf, ok := room.ExitFlags[d]; ok {
-				if exf.Closed {
-					continue // prevents yells from going through doors...  may revisit this in the future.
-				}
+		for _, exit := range room.GetExits() {
+			if exit.IsClosed() {
+				continue // prevents yells from going through doors...  may revisit this in the future.
+			}
+			if to_room := exit.GetTarget(); to_room != nil {
+				yell(entity, words, to_room.GetId(), dist+1, visited)
 			}
-			yell(entity, words, e, dist+1, visited)
 		}
 	}
 }
@@ -118,10 +110,6 @@ func yell(entity Entity, words string, roomId uint, dist uint, visited []uint) {
 func do_emote(entity Entity, args ...string) {
 	emote := strings.Join(args, " ")
 	speaker := entity.GetCharData()
-	if entity_unspeakable_state(entity) {
-		entity.Send("\r\n&dYou are %s.&d\r\n", entity_unspeakable_reason(entity))
-		return
-	}
 	speaker.GetRoom().SendToRoom(sprintf("&d%s %s&d\r\n", speaker.Name, emote))
 }
 func do_say_comlink(entity Entity, args ...string) {
@@ -129,10 +117,6 @@ func do_say_comlink(entity Entity, args ...string) {
 	words = strings.TrimSpace(words)
 	speaker := entity.GetCharData()
 	speaker_freq := entity.(*PlayerProfile).Frequency
-	if entity_unspeakable_state(entity) {
-		entity.Send("\r\n&dYou are %s.&d\r\n", entity_unspeakable_reason(entity))
-		return
-	}
 	if words == "" {
 		entity.Send("\r\n%s\r\n", MakeTitle("Comlink Status", ANSI_TITLE_STYLE_SYSTEM, ANSI_TITLE_ALIGNMENT_LEFT))
 		entity.Send("&GComlink&d: %-32s\r\n\r\n", "PIC//113 Kuat Systems Intercom")
@@ -185,10 +169,6 @@ func do_broadcast_comlink(freq string, message string) {
 func do_tune_frequency(entity Entity, args ...string) {
 	if entity.IsPlayer() {
 		player := entity.(*PlayerProfile)
-		if entity_unspeakable_state(entity) {
-			entity.Send("\r\n&dYou are %s.&d\r\n", entity_unspeakable_reason(entity))
-			return
-		}
 		if len(args) > 0 {
 			freq, err := strconv.ParseFloat(args[0], 32)
 			if err != nil {
@@ -219,10 +199,6 @@ func do_speak(entity Entity, args ...string) {
 		entity.Send("\r\n&CSyntax: speak <language>&d\r\n")
 		return
 	}
-	if entity_unspeakable_state(entity) {
-		entity.Send("\r\n&dYou are %s.&d\r\n", entity_unspeakable_reason(entity))
-		return
-	}
 	ch := entity.GetCharData()
 	language := language_get_by_name(args[0])
 	if language != nil {
@@ -19,6 +19,7 @@ package swr
 
 import (
 	"log"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -30,7 +31,11 @@ func do_quit(entity Entity, args ...string) {
 			return
 		}
 		player := entity.(*PlayerProfile)
-		DB().SavePlayerData(player)
+		if err := DB().SavePlayerData(player); err != nil {
+			ErrorCheck(err)
+			entity.Send("\r\n&RUnable to save your character, you had better stay a while. Please tell an immortal.&d\r\n")
+			return
+		}
 		entity.Send("\r\n&CThe world slowly fades away as you close your eyes and leave the game...&d\r\n\r\n")
 		entity.GetCharData().State = ENTITY_STATE_SLEEPING
 		ScheduleFunc(func() {
@@ -162,24 +167,26 @@ func do_examine(entity Entity, args ...string) {
 		object = room.FindItem(object_name)
 	}
 	if object == nil {
-		for _, e := range room.GetEntities() {
-			if strings.HasPrefix(e.GetCharData().Name, object_name) {
-				entity.Send("&dYou look at &W%s&d and see...\r\n%s\r\n", e.GetCharData().Name, e.GetCharData().Desc)
-				entity.Send("&YEquipment:\r\n-------------------------------------&d\r\n")
-				if len(e.GetCharData().Equipment) == 0 {
-					entity.Send("Nothing\r\n")
-				} else {
-					entity.Send("&YHead: &d%-26s\r\n", entity_get_equipment_for_slot(entity, "head"))
-					entity.Send("&YTorso: &d%-26s\r\n", entity_get_equipment_for_slot(entity, "torso"))
-					entity.Send("&YWaist: &d%-26s\r\n", entity_get_equipment_for_slot(entity, "waist"))
-					entity.Send("&YLegs: &d%-26s\r\n", entity_get_equipment_for_slot(entity, "legs"))
-					entity.Send("&YFeet: &d%-26s\r\n", entity_get_equipment_for_slot(entity, "feet"))
-					entity.Send("&YHands: &d%-26s\r\n", entity_get_equipment_for_slot(entity, "hands"))
-					entity.Send("&Y--------------------------------------&d\r\n")
-					entity.Send("&RWeapon: &d%-26s\r\n", entity_get_equipment_for_slot(entity, "weapon"))
-				}
-				return
+		if e := target_entity(entity, room.GetEntities(), object_name); e != nil {
+			entity.Send("&dYou look at &W%s&d and see...\r\n%s\r\n", e.GetCharData().Name, e.GetCharData().Desc)
+			entity.Send("&YEquipment:\r\n-------------------------------------&d\r\n")
+			if len(e.GetCharData().Equipment) == 0 {
+				entity.Send("Nothing\r\n")
+			} else {
+				entity.Send("&YHead: &d%-26s\r\n", entity_get_equipment_for_slot(e, "head"))
+				entity.Send("&YTorso: &d%-26s\r\n", entity_get_equipment_for_slot(e, "torso"))
+				entity.Send("&YWaist: &d%-26s\r\n", entity_get_equipment_for_slot(e, "waist"))
+				entity.Send("&YLegs: &d%-26s\r\n", entity_get_equipment_for_slot(e, "legs"))
+				entity.Send("&YFeet: &d%-26s\r\n", entity_get_equipment_for_slot(e, "feet"))
+				entity.Send("&YHands: &d%-26s\r\n", entity_get_equipment_for_slot(e, "hands"))
+				entity.Send("&Y--------------------------------------&d\r\n")
+				entity.Send("&RWeapon: &d%-26s\r\n", entity_get_equipment_for_slot(e, "weapon"))
 			}
+			return
+		}
+		if extra := extra_desc_look(entity, room, object_name); extra != nil {
+			entity.Send("\r\n&W%s&d\r\n", telnet_encode(extra.Desc))
+			return
 		}
 		entity.Send("\r\nCan't find that here.\r\n")
 		return
@@ -284,12 +291,38 @@ func do_remove(entity Entity, args ...string) {
 }
 
 func do_time(entity Entity, args ...string) {
-	now := time.Now()
 	entity.Send("\r\n&BHolonet Time Synchronization&d\r\n")
 	entity.Send("&g----------------------------------------------------------------&d\r\n")
-	entity.Send("&cThe Current Local Time is: &Y%s&d\r\n", now.Format(time.RFC822))
-	entity.Send("&cThe Current UTC Time is: &Y%s&d\r\n", now.UTC().Format(time.RFC822Z))
+	entity.Send("&cGalactic Standard Time is: &Y%s&d\r\n", GetGameTime())
+	if w := room_get_weather(entity.GetRoom()); w != nil {
+		day := "night"
+		if w.IsDay() {
+			day = "day"
+		}
+		entity.Send("&cOn &W%s&c it is hour &Y%d&c of &Y%d&c, %s. The weather is &Y%s&c.&d\r\n", w.Planet, w.GetHour(), w.Day, day, w.GetState().Name)
+	}
 	entity.Send("&cThe Server Started at: &Y%s&d\r\n", startup.Format(time.RFC822))
 	entity.Send("&CThe Server has been running for &Y%s&d\r\n", time.Since(startup).String())
 	entity.Send("\r\n")
 }
+
+func do_map(entity Entity, args ...string) {
+	room := DB().GetRoom(entity.RoomId(), entity.ShipId())
+	if room == nil {
+		return
+	}
+	radius := map_sizes["medium"]
+	if len(args) > 0 {
+		if r, ok := map_sizes[strings.ToLower(args[0])]; ok {
+			radius = r
+		} else if r, err := strconv.Atoi(args[0]); err == nil && r > 0 && r <= map_sizes["large"] {
+			radius = r
+		} else {
+			entity.Send("\r\n&RSyntax: map [small|medium|large|1-%d]&d\r\n", map_sizes["large"])
+			return
+		}
+	}
+	entity.Send("\r\n%s\r\n", MakeTitle(room.Name, ANSI_TITLE_STYLE_NORMAL, ANSI_TITLE_ALIGNMENT_CENTER))
+	entity.Send("%s&d\r\n", map_render(entity, room, radius))
+	entity.Send("%s&d\r\n", map_legend)
+}
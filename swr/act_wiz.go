@@ -19,7 +19,7 @@ package swr
 
 import (
 	"log"
-	"os"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -39,26 +39,34 @@ func do_area_create(entity Entity, args ...string) {
 	ErrorCheck(err)
 	max_vnum, err := strconv.ParseInt(args[2], 10, 32)
 	ErrorCheck(err)
+	if min_vnum < 1 || max_vnum <= min_vnum {
+		entity.Send("\r\n&RThe max vnum has to be bigger than the min vnum.&d\r\n")
+		return
+	}
 	db := DB()
-	for _, area := range db.areas {
-		for _, r := range area.Rooms {
-			if uint(min_vnum) < r.Id && r.Id < uint(max_vnum) {
-				entity.Send("\r\n&RError! Vnum range already exists!&d\r\n")
-				return
-			}
-		}
+	if db.GetArea(args[0]) != nil {
+		entity.Send("\r\n&RError! Area already exists!&d\r\n")
+		return
 	}
 	area := new(AreaData)
 	area.Name = args[0]
+	// new areas own the same block of room, mob and item vnums.
+	for _, kind := range vnum_kinds {
+		area.SetVnums(kind, uint(min_vnum), uint(max_vnum-1))
+	}
+	if err := area_vnum_conflict(area, db.GetAreas()); err != nil {
+		entity.Send("\r\n&RError! &W%s&d\r\n", err.Error())
+		return
+	}
 	area.Author = entity.GetCharData().Name
 	area.Rooms = make([]RoomData, 0)
 	area.Items = make([]ItemSpawn, 0)
 	area.Mobs = make([]MobSpawn, 0)
 	area.Levels = []uint16{1, 100}
-	area.Reset = 300
-	area.ResetMsg = "The world seems to shift around you."
+	area.SetReset(300)
+	area.SetResetMsg("The world seems to shift around you.")
 	for i := min_vnum; i < max_vnum; i++ {
-		room := RoomData{
+		room := &RoomData{
 			Id:        uint(i),
 			Name:      "A void",
 			Desc:      "Somewhere in the void of space.",
@@ -66,12 +74,16 @@ func do_area_create(entity Entity, args ...string) {
 			Items:     make([]Item, 0),
 			Exits:     make(map[string]uint),
 			ExitFlags: make(map[string]*RoomExitFlag),
-			Area:      area,
 			RoomProgs: make(map[string]string),
 		}
-		area.Rooms = append(area.Rooms, room)
+		area.SetRoom(room.Id, room)
+	}
+	db.AddArea(area)
+	if err := area.Save(); err != nil {
+		entity.Send("\r\n&RUnable to save area: &W%s&d\r\n", err.Error())
+		return
 	}
-	db.SaveArea(area)
+	area_reset(area)
 	entity.Send("\r\n&YArea Create. Ok.&d\r\n")
 }
 
@@ -82,7 +94,8 @@ func do_area_set(entity Entity, args ...string) {
 	if entity.IsPlayer() {
 		player := entity.(*PlayerProfile)
 		room := DB().GetRoom(player.RoomId(), player.ShipId())
-		if room.Area == nil {
+		area := room.GetArea()
+		if area == nil {
 			entity.Send("\r\n&RNot in an area!&d\r\n")
 			return
 		}
@@ -90,12 +103,12 @@ func do_area_set(entity Entity, args ...string) {
 			entity.Send("\r\nSyntax aset <field> <value>\r\n")
 			entity.Send("-------------------------------------\r\n")
 			entity.Send("Available Fields:\r\n")
-			entity.Send("name, levels, author, reset, resetMsg")
+			entity.Send("name, levels, author, reset, resetMsg, roomvnums, mobvnums, itemvnums, sector, flags")
 			return
 		}
 		switch strings.ToLower(args[0]) {
 		case "name":
-			room.Area.Name = strings.TrimSpace(strings.Join(args[1:], " "))
+			area.SetName(strings.TrimSpace(strings.Join(args[1:], " ")))
 		case "levels":
 			if len(args) != 3 {
 				entity.Send("\r\nSyntax: aset levels <min> <max>\r\n")
@@ -103,16 +116,60 @@ func do_area_set(entity Entity, args ...string) {
 			} else {
 				min, _ := strconv.Atoi(args[1])
 				max, _ := strconv.Atoi(args[2])
-				room.Area.Levels[0] = uint16(min)
-				room.Area.Levels[1] = uint16(max)
+				levels := area.GetLevels()
+				levels[0] = uint16(min)
+				levels[1] = uint16(max)
 			}
 		case "author":
-			room.Area.Author = strings.TrimSpace(strings.Join(args[1:], " "))
+			area.SetAuthor(strings.TrimSpace(strings.Join(args[1:], " ")))
 		case "reset":
 			r, _ := strconv.Atoi(args[1])
-			room.Area.Reset = uint(r)
+			area.SetReset(uint(r))
 		case "resetmsg":
-			room.Area.ResetMsg = strings.TrimSpace(strings.Join(args[1:], " "))
+			area.SetResetMsg(strings.TrimSpace(strings.Join(args[1:], " ")))
+		case "sector":
+			if !is_sector(args[1]) {
+				entity.Send("\r\n&RInvalid sector.&d\r\n")
+				return
+			}
+			area.(*AreaData).Sector = args[1]
+		case "flags":
+			// aset flags no_pk toggles it for every room in the area.
+			a := area.(*AreaData)
+			flag := strings.ToLower(args[1])
+			if !is_room_flag(flag) {
+				entity.Send("\r\n&RInvalid flag, one of %s.&d\r\n", strings.Join(room_flag_list, ", "))
+				return
+			}
+			if slice_contains_string(a.Flags, flag) {
+				a.Flags = slice_remove_string(a.Flags, flag)
+			} else {
+				a.Flags = append(a.Flags, flag)
+			}
+		case "roomvnums", "mobvnums", "itemvnums":
+			if len(args) != 3 {
+				entity.Send("\r\nSyntax: aset %s <min> <max>\r\n", strings.ToLower(args[0]))
+				return
+			}
+			kind := strings.TrimSuffix(strings.ToLower(args[0]), "vnums")
+			min, _ := strconv.Atoi(args[1])
+			max, _ := strconv.Atoi(args[2])
+			if min < 1 || max < min {
+				entity.Send("\r\n&RThe max vnum can't be less than the min vnum.&d\r\n")
+				return
+			}
+			a := area.(*AreaData)
+			prev := a.GetVnums(kind)
+			a.SetVnums(kind, uint(min), uint(max))
+			if err := area_vnum_conflict(a, DB().GetAreas()); err != nil {
+				if prev != nil {
+					a.SetVnums(kind, prev[0], prev[1])
+				} else {
+					a.SetVnums(kind, 0, 0)
+				}
+				entity.Send("\r\n&R%s&d\r\n", err.Error())
+				return
+			}
 		default:
 			entity.Send("\r\n&RInvalid field.&d\r\n")
 		}
@@ -138,7 +195,10 @@ func do_area_reset(entity Entity, args ...string) {
 	} else {
 		for i, area := range DB().areas {
 			if strings.EqualFold(area.Name, args[0]) {
-				area_reset(DB().areas[i])
+				if err := DB().areas[i].Reset(); err != nil {
+					entity.Send("\r\n&RArea reset with errors: &W%s&d\r\n", err.Error())
+					return
+				}
 				entity.Send("\r\n&YArea Reset. Ok.&d\r\n")
 				return
 			}
@@ -151,11 +211,18 @@ func do_area_save(entity Entity, args ...string) {
 		return
 	}
 	if entity.IsPlayer() {
-		DB().SaveMobs()
-		DB().SaveItems()
+		if err := DB().SaveMobs(); err != nil {
+			entity.Send("\r\n&RUnable to save mobs: &W%s&d\r\n", err.Error())
+		}
+		if err := DB().SaveItems(); err != nil {
+			entity.Send("\r\n&RUnable to save items: &W%s&d\r\n", err.Error())
+		}
 		room := DB().GetRoom(entity.RoomId(), entity.ShipId())
-		if room.Area != nil {
-			DB().SaveArea(room.Area)
+		if area := room.GetArea(); area != nil {
+			if err := area.Save(); err != nil {
+				entity.Send("\r\n&RUnable to save area: &W%s&d\r\n", err.Error())
+				return
+			}
 			entity.Send("\r\n&YArea Save. Ok.&d\r\n")
 		} else {
 			entity.Send("\r\n&RNot in an area file!&d\r\n")
@@ -163,6 +230,397 @@ func do_area_save(entity Entity, args ...string) {
 	}
 }
 
+// do_resets lists and edits the area's resets, what they spawn and how often.
+func do_resets(entity Entity, args ...string) {
+	if !entity.IsPlayer() {
+		return
+	}
+	db := DB()
+	room := entity.GetRoom()
+	area := room_reset_area(room)
+	if area == nil {
+		entity.Send("\r\n&RNot in an area!&d\r\n")
+		return
+	}
+	item_name := func(id uint) string {
+		if item := db.GetItem(id); item != nil {
+			return item.GetData().Name
+		}
+		return "&Rmissing&d"
+	}
+	where := func(room uint, ship uint) string {
+		if ship > 0 {
+			return sprintf("room %d ship %d", room, ship)
+		}
+		return sprintf("room %d", room)
+	}
+	limits := func(chance uint, max_world uint, max_room uint) string {
+		ret := ""
+		if chance > 0 {
+			ret += sprintf(" &Gchance &W%d%%", chance)
+		}
+		if max_world > 0 {
+			ret += sprintf(" &Gmax world &W%d", max_world)
+		}
+		if max_room > 0 {
+			ret += sprintf(" &Gmax room &W%d", max_room)
+		}
+		return ret
+	}
+	var list_items func(label string, items []ResetItem, depth int)
+	list_items = func(label string, items []ResetItem, depth int) {
+		for _, ri := range items {
+			entity.Send("%s&G%-7s &W%-6d&d %s%s&d\r\n", strings.Repeat("   ", depth), label, ri.Item, item_name(ri.Item), limits(ri.Chance, 0, 0))
+			list_items("inside", ri.Contents, depth+1)
+		}
+	}
+	if len(args) == 0 {
+		entity.Send("\r\n%s\r\n", MakeTitle(sprintf("Resets for %s", area.Name), ANSI_TITLE_STYLE_NORMAL, ANSI_TITLE_ALIGNMENT_CENTER))
+		entity.Send("&WMobs:&d\r\n")
+		for i, spawn := range area.Mobs {
+			name := "&Rmissing&d"
+			if mob := db.GetMob(spawn.Mob); mob != nil {
+				name = mob.GetCharData().Name
+			}
+			entity.Send("&Y[&W%3d&Y]&d &W%-6d&d %s &Gin &W%s%s&d\r\n", i, spawn.Mob, name, where(spawn.Room, spawn.Ship), limits(spawn.Chance, spawn.MaxWorld, spawn.MaxRoom))
+			list_items("equip", spawn.Equip, 2)
+			list_items("give", spawn.Give, 2)
+		}
+		entity.Send("&WObjects:&d\r\n")
+		for i, spawn := range area.Items {
+			entity.Send("&Y[&W%3d&Y]&d &W%-6d&d %s &Gin &W%s%s&d\r\n", i, spawn.Item, item_name(spawn.Item), where(spawn.Room, spawn.Ship), limits(spawn.Chance, spawn.MaxWorld, spawn.MaxRoom))
+			list_items("put", spawn.Contents, 2)
+		}
+		entity.Send("&WDoors:&d\r\n")
+		for i, door := range area.Doors {
+			entity.Send("&Y[&W%3d&Y]&d %s &W%s&d %s\r\n", i, where(door.Room, door.Ship), door.Direction, door.State)
+		}
+		return
+	}
+	syntax := func() {
+		entity.Send("\r\nSyntax: resets\r\n")
+		entity.Send("        resets mob <#> chance|maxworld|maxroom <value>\r\n")
+		entity.Send("        resets mob <#> equip|give <item vnum> [chance]\r\n")
+		entity.Send("        resets mob <#> take <item vnum>\r\n")
+		entity.Send("        resets obj <#> chance|maxworld|maxroom <value>\r\n")
+		entity.Send("        resets obj <#> put <item vnum> [chance]\r\n")
+		entity.Send("        resets obj <#> take <item vnum>\r\n")
+		entity.Send("        resets door <dir> open|closed|locked|none\r\n")
+		entity.Send("-----------------------------------------------------------\r\n")
+		entity.Send("Use mspawn and ospawn to add mob and object resets, mremove and oremove to delete them.\r\n")
+	}
+	kind := strings.ToLower(args[0])
+	if kind == "door" {
+		if len(args) != 3 {
+			syntax()
+			return
+		}
+		dir := get_direction_string(args[1])
+		state := strings.ToLower(args[2])
+		for i := len(area.Doors) - 1; i >= 0; i-- {
+			if area.Doors[i].Room == room.Id && area.Doors[i].Ship == room.ship && area.Doors[i].Direction == dir {
+				area.Doors = append(area.Doors[:i], area.Doors[i+1:]...)
+			}
+		}
+		switch state {
+		case "none":
+			entity.Send("\r\n&YDoor reset removed. Ok.&d\r\n")
+			return
+		case DOOR_OPEN, DOOR_CLOSED, DOOR_LOCKED:
+		default:
+			syntax()
+			return
+		}
+		door := DoorReset{Room: room.Id, Ship: room.ship, Direction: dir, State: state}
+		if err := area_reset_door(&door); err != nil {
+			entity.Send("\r\n&R%s&d\r\n", err.Error())
+			return
+		}
+		area.Doors = append(area.Doors, door)
+		entity.Send("\r\n&YDoor reset. Ok.&d\r\n")
+		return
+	}
+	if len(args) < 4 || (kind != "mob" && kind != "obj") {
+		syntax()
+		return
+	}
+	index, err := strconv.Atoi(args[1])
+	if err != nil || index < 0 || (kind == "mob" && index >= len(area.Mobs)) || (kind == "obj" && index >= len(area.Items)) {
+		entity.Send("\r\n&RNo %s reset &W%s&R, see &Wresets&R.&d\r\n", kind, args[1])
+		return
+	}
+	field := strings.ToLower(args[2])
+	value, err := strconv.Atoi(args[3])
+	if err != nil || value < 0 {
+		entity.Send("\r\n&RUnable to parse &W%s&R as a number.&d\r\n", args[3])
+		return
+	}
+	// the item lists a reset can hold, equip and give for mobs, put for objects.
+	var list *[]ResetItem
+	var chance, max_world, max_room *uint
+	if kind == "mob" {
+		spawn := &area.Mobs[index]
+		chance, max_world, max_room = &spawn.Chance, &spawn.MaxWorld, &spawn.MaxRoom
+		switch field {
+		case "equip":
+			list = &spawn.Equip
+		case "give":
+			list = &spawn.Give
+		}
+	} else {
+		spawn := &area.Items[index]
+		chance, max_world, max_room = &spawn.Chance, &spawn.MaxWorld, &spawn.MaxRoom
+		if field == "put" {
+			list = &spawn.Contents
+		}
+	}
+	switch field {
+	case "chance":
+		if value > 100 {
+			value = 100
+		}
+		*chance = uint(value)
+	case "maxworld":
+		*max_world = uint(value)
+	case "maxroom":
+		*max_room = uint(value)
+	case "equip", "give", "put":
+		if list == nil {
+			syntax()
+			return
+		}
+		item := db.GetItem(uint(value))
+		if item == nil {
+			entity.Send("\r\n&RUnable to find item with vnum: &W%d&d\r\n", value)
+			return
+		}
+		if field == "equip" && item_wear_slot(item) == "" {
+			entity.Send("\r\n&W%s&R can't be equipped, use give.&d\r\n", item.GetData().Name)
+			return
+		}
+		ri := ResetItem{Item: uint(value)}
+		if len(args) > 4 {
+			c, _ := strconv.Atoi(args[4])
+			if c > 0 && c < 100 {
+				ri.Chance = uint(c)
+			}
+		}
+		*list = append(*list, ri)
+	case "take":
+		removed := false
+		lists := make([]*[]ResetItem, 0)
+		if kind == "mob" {
+			lists = append(lists, &area.Mobs[index].Equip, &area.Mobs[index].Give)
+		} else {
+			lists = append(lists, &area.Items[index].Contents)
+		}
+		for _, l := range lists {
+			for i := len(*l) - 1; i >= 0; i-- {
+				if (*l)[i].Item == uint(value) {
+					*l = append((*l)[:i], (*l)[i+1:]...)
+					removed = true
+					break
+				}
+			}
+			if removed {
+				break
+			}
+		}
+		if !removed {
+			entity.Send("\r\n&RThat reset doesn't have item &W%d&R.&d\r\n", value)
+			return
+		}
+	default:
+		syntax()
+		return
+	}
+	entity.Send("\r\n&YResets. Ok.&d\r\n")
+}
+
+// do_vnums lists the vnum ranges each area owns and the blocks nobody owns yet, or with an area
+// name, how much of that area's ranges are used.
+func do_vnums(entity Entity, args ...string) {
+	if !entity.IsPlayer() {
+		return
+	}
+	db := DB()
+	areas := db.GetAreas()
+	range_string := func(r []uint) string {
+		if r == nil {
+			return "none"
+		}
+		return sprintf("%d-%d", r[0], r[1])
+	}
+	if len(args) > 0 {
+		area := db.GetArea(args[0])
+		if area == nil {
+			entity.Send("\r\n&RArea not found.&d\r\n")
+			return
+		}
+		entity.Send("\r\n%s\r\n", MakeTitle(sprintf("Vnums for %s", area.Name), ANSI_TITLE_STYLE_NORMAL, ANSI_TITLE_ALIGNMENT_CENTER))
+		for _, kind := range vnum_kinds {
+			r := area.GetVnums(kind)
+			if r == nil {
+				entity.Send("&Y%-5s&d none, set them with &Waset %svnums <min> <max>&d\r\n", kind, kind)
+				continue
+			}
+			used := 0
+			for i := r[0]; i <= r[1]; i++ {
+				if vnum_is_used(kind, i) {
+					used++
+				}
+			}
+			next := "none"
+			switch kind {
+			case VNUM_MOB:
+				if id := db.GetNextMobVnum(area); id > 0 {
+					next = strconv.Itoa(int(id))
+				}
+			case VNUM_ITEM:
+				if id := db.GetNextItemVnum(area); id > 0 {
+					next = strconv.Itoa(int(id))
+				}
+			default:
+				for i := r[0]; i <= r[1]; i++ {
+					if !vnum_is_used(kind, i) {
+						next = strconv.Itoa(int(i))
+						break
+					}
+				}
+			}
+			entity.Send("&Y%-5s&d &W%-13s&d used &W%d&d of &W%d&d, next free &W%s&d\r\n", kind, range_string(r), used, r[1]-r[0]+1, next)
+		}
+		return
+	}
+	entity.Send("\r\n%s\r\n", MakeTitle("Vnums", ANSI_TITLE_STYLE_NORMAL, ANSI_TITLE_ALIGNMENT_CENTER))
+	entity.Send("&W%-16s %-13s %-13s %-13s&d\r\n", "Area", "Rooms", "Mobs", "Items")
+	for _, area := range areas {
+		entity.Send("&Y%-16s&d %-13s %-13s %-13s\r\n", area.Name, range_string(area.GetVnums(VNUM_ROOM)), range_string(area.GetVnums(VNUM_MOB)), range_string(area.GetVnums(VNUM_ITEM)))
+	}
+	entity.Send("\r\n&WFree blocks:&d\r\n")
+	for _, kind := range vnum_kinds {
+		ranges := make([][]uint, 0)
+		for _, area := range areas {
+			if r := area.GetVnums(kind); r != nil {
+				ranges = append(ranges, r)
+			}
+		}
+		sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+		free := make([]string, 0)
+		next := uint(1)
+		for _, r := range ranges {
+			if r[0] > next {
+				free = append(free, sprintf("%d-%d", next, r[0]-1))
+			}
+			if r[1]+1 > next {
+				next = r[1] + 1
+			}
+		}
+		free = append(free, sprintf("%d+", next))
+		entity.Send("&Y%-5s&d %s\r\n", kind, strings.Join(free, ", "))
+	}
+}
+
+// vnum_is_used is true if there's a VNUM_* kind thing with the vnum in the game.
+func vnum_is_used(kind string, vnum uint) bool {
+	db := DB()
+	switch kind {
+	case VNUM_ROOM:
+		return db.GetRoom(vnum, 0) != nil
+	case VNUM_MOB:
+		return db.GetMob(vnum) != nil
+	case VNUM_ITEM:
+		return db.GetItem(vnum) != nil
+	}
+	return false
+}
+
+// do_area_load puts an area that isn't in the game yet, a new file in data/areas say, into the game.
+func do_area_load(entity Entity, args ...string) {
+	if len(args) == 0 {
+		entity.Send("\r\nSyntax: areaload <areaname>\r\n")
+		return
+	}
+	db := DB()
+	if area := db.GetArea(args[0]); area != nil {
+		entity.Send("\r\n&RArea &W%s&R is already loaded, use areareload.&d\r\n", area.Name)
+		return
+	}
+	area, err := db.LoadArea(args[0])
+	if err != nil {
+		entity.Send("\r\n&RUnable to load area: &W%s&d\r\n", err.Error())
+		return
+	}
+	if err := area_vnum_conflict(area, db.GetAreas()); err != nil {
+		entity.Send("\r\n&R%s&d\r\n", err.Error())
+		return
+	}
+	for _, r := range area.Rooms {
+		if room := db.GetRoom(r.Id, 0); room != nil {
+			entity.Send("\r\n&RRoom &W%d&R is already used by &W%s&R.&d\r\n", r.Id, room.GetArea().GetName())
+			return
+		}
+	}
+	db.AddArea(area)
+	area_reset(area)
+	log.Printf("%s loaded area %s.", entity.GetCharData().Name, area.Name)
+	entity.Send("\r\n&YArea &W%s&Y loaded with &W%d&Y rooms. Ok.&d\r\n", area.Name, len(area.Rooms))
+}
+
+// do_area_reload re-reads an area that's in the game, so builders can edit the file without a reboot.
+func do_area_reload(entity Entity, args ...string) {
+	if len(args) == 0 {
+		entity.Send("\r\nSyntax: areareload <areaname>\r\n")
+		return
+	}
+	db := DB()
+	old := db.GetArea(args[0])
+	if old == nil {
+		entity.Send("\r\n&RArea not found, use areaload for new areas.&d\r\n")
+		return
+	}
+	area, err := db.LoadArea(old.Name)
+	if err != nil {
+		entity.Send("\r\n&RUnable to load area: &W%s&d\r\n", err.Error())
+		return
+	}
+	if area.Name != old.Name {
+		entity.Send("\r\n&RThe file names the area &W%s&R, not &W%s&R.&d\r\n", area.Name, old.Name)
+		return
+	}
+	if err := area_vnum_conflict(area, db.GetAreas()); err != nil {
+		entity.Send("\r\n&R%s&d\r\n", err.Error())
+		return
+	}
+	for _, r := range area.Rooms {
+		if room := db.GetRoom(r.Id, 0); room != nil && room.Area != old {
+			entity.Send("\r\n&RRoom &W%d&R is already used by &W%s&R.&d\r\n", r.Id, room.GetArea().GetName())
+			return
+		}
+	}
+	changes := db.ReloadArea(area)
+	area_reset(area)
+	log.Printf("%s reloaded area %s.", entity.GetCharData().Name, area.Name)
+	vnums := func(ids []uint) string {
+		s := make([]string, 0)
+		for _, id := range ids {
+			s = append(s, strconv.Itoa(int(id)))
+		}
+		if len(s) > 10 {
+			s = append(s[:10], "...")
+		}
+		return strings.Join(s, " ")
+	}
+	entity.Send("\r\n&YArea &W%s&Y reloaded.&d\r\n", area.Name)
+	entity.Send("&YRooms added:   &W%4d&d %s\r\n", len(changes.Added), vnums(changes.Added))
+	entity.Send("&YRooms changed: &W%4d&d %s\r\n", len(changes.Changed), vnums(changes.Changed))
+	entity.Send("&YRooms removed: &W%4d&d %s\r\n", len(changes.Removed), vnums(changes.Removed))
+	if changes.Entities > 0 || changes.Items > 0 {
+		entity.Send("&YMoved &W%d&Y entities and &W%d&Y items out of removed rooms.&d\r\n", changes.Entities, changes.Items)
+	}
+	entity.Send("&YMobs kept: &W%d&Y, despawned: &W%d&d\r\n", changes.Kept, changes.Despawned)
+}
+
 func do_room_stat(entity Entity, args ...string) {
 	if len(args) > 2 {
 		entity.Send("\r\nSyntax: rstat <vnum?> <shipId?>     | vnum and shipId are optional, but vnum must be supplied with shipId.\r\n")
@@ -206,23 +664,66 @@ func do_room_stat(entity Entity, args ...string) {
 	entity.Send("\r\n%s\r\n", MakeTitle("Room Stat", ANSI_TITLE_STYLE_SYSTEM, ANSI_TITLE_ALIGNMENT_LEFT))
 	entity.Send("     &GName: &W\"%s\"&d\r\n", room.Name)
 	entity.Send("     &GVNum: &W%-7d &GShip: &W%s&d\r\n", room.Id, ship)
-	entity.Send("     &GArea: &W%s&d\r\n", room.Area.Name)
+	area := room.GetArea()
+	if area != nil {
+		entity.Send("     &GArea: &W%s&d\r\n", area.GetName())
+	} else {
+		entity.Send("     &GArea: &WNone&d\r\n")
+	}
 	entity.Send("    &GFlags: &W%v&d\r\n", room.Flags)
+	if room.Area != nil && len(room.Area.Flags) > 0 {
+		entity.Send("&GEffective: &W%v &G(area: &W%v&G)&d\r\n", room_get_flags(room), room.Area.Flags)
+	}
+	sector := room_get_sector(room)
+	entity.Send("   &GSector: &W%-10s &GMv: &W%d&d\r\n", sector.Name, sector.Mv)
+	if c, ok := room_coords(room); ok {
+		entity.Send("   &GCoords: &W%d, %d, %d&d\r\n", c[0], c[1], c[2])
+	} else {
+		entity.Send("   &GCoords: &WNone&d\r\n")
+	}
 	entity.Send("     &GDesc: &W\"%s\"&d\r\n", room.Desc)
-	entity.Send("    &GExits: &W%+v&d\r\n", room.Exits)
-	entity.Send("&GExitFlags: &W%+v&d\r\n", room.ExitFlags)
+	entity.Send("    &GExits:&d\r\n")
+	for _, exit := range room.GetExits() {
+		to := "nowhere"
+		if to_room := exit.GetTarget(); to_room != nil {
+			to = sprintf("%d", to_room.GetId())
+		}
+		door := ""
+		if exit.IsDoor() {
+			f := room.GetExitFlags(exit.GetDirection())
+			door = sprintf("&C%s %s&Gkey: &W%d", f.GetName(), room_get_exit_status(exit), exit.GetKeyId())
+			if f.Hidden {
+				door += " &Chidden"
+			}
+			if f.Difficulty > 0 {
+				door += sprintf(" &Gpick: &W%d %s", f.Difficulty, f.GetPickSkill())
+			}
+		}
+		entity.Send("           &G%-10s &W%-7s %s&d\r\n", exit.GetDirection(), to, door)
+	}
 	entity.Send("&GRoomProgs: &d\r\n")
 	for name, value := range room.RoomProgs {
 		entity.Send("&y%s&w:%s&d\r\n", name, value)
 	}
+	entity.Send("   &GExtras: &d\r\n")
+	for _, e := range room.Extras {
+		entity.Send("           &W%s&d\r\n", strings.Join(e.Keywords, ", "))
+	}
 	entity.Send("   &GSpawns: &d\r\n")
-	for _, ms := range room.Area.Mobs {
+	if area == nil {
+		entity.Send("\r\n")
+		return
+	}
+	for _, ms := range area.GetMobSpawns() {
 		if ms.Room != room.Id {
 			continue
 		}
 		mob := DB().GetMob(ms.Mob)
+		if mob == nil {
+			continue
+		}
 		m := mob.GetCharData()
-		entity.Send(sprintf("&Y[&W%d&Y]&d%-26s", m.OId, tstring(m.Name, 23)))
+		entity.Send(sprintf("&Y[&W%d&Y]&d%-26s", m.Id, tstring(m.Name, 23)))
 	}
 	entity.Send("\r\n")
 
@@ -240,7 +741,7 @@ func do_room_set(entity Entity, args ...string) {
 		entity.Send("\r\nSyntax rset <field> <value>\r\n")
 		entity.Send("-------------------------------------\r\n")
 		entity.Send("Available Fields:\r\n")
-		entity.Send("name, desc, flags")
+		entity.Send("name, desc, flags, extra, door, coords, sector")
 		return
 	}
 	switch args[0] {
@@ -249,21 +750,98 @@ func do_room_set(entity Entity, args ...string) {
 	case "desc":
 		room.Desc = consolify(strings.TrimSpace(strings.Join(args[1:], " ")))
 	case "flags":
+		if !is_room_flag(args[1]) {
+			entity.Send("\r\n&RInvalid flag, one of %s.&d\r\n", strings.Join(room_flag_list, ", "))
+			return
+		}
 		if room.HasFlag(args[1]) {
 			room.RemoveFlag(args[1])
 		} else {
 			room.SetFlag(args[1])
 		}
+	case "extra":
+		// rset extra sign,signs The sign reads... or just the keywords to remove it.
+		keywords := strings.Split(strings.ToLower(args[1]), ",")
+		room.Extras = extra_desc_set(room.Extras, keywords, consolify(strings.TrimSpace(strings.Join(args[2:], " "))))
+	case "sector":
+		// rset sector none to use the area's.
+		if args[1] == "none" {
+			room.Sector = ""
+			break
+		}
+		if !is_sector(args[1]) {
+			entity.Send("\r\n&RInvalid sector.&d\r\n")
+			return
+		}
+		room.Sector = args[1]
+	case "coords":
+		// rset coords 3 -2 0, or rset coords none to lay the room out from its exits.
+		if args[1] == "none" {
+			room.Coords = nil
+			break
+		}
+		if len(args) != 4 {
+			entity.Send("\r\nSyntax: rset coords <x> <y> <z>, or rset coords none\r\n")
+			return
+		}
+		coords := make([]int, 3)
+		for i := range coords {
+			c, err := strconv.Atoi(args[i+1])
+			if err != nil {
+				entity.Send("\r\n&RCoordinates are whole numbers.&d\r\n")
+				return
+			}
+			coords[i] = c
+		}
+		room.Coords = coords
+	case "door":
+		// rset door north name blast door, rset door north hidden, rset door north difficulty 40
+		if len(args) < 3 {
+			entity.Send("\r\nSyntax: rset door <dir> name|hidden|difficulty|keypad|key <value>\r\n")
+			return
+		}
+		dir := get_direction_string(args[1])
+		if room.GetExit(dir) == nil {
+			entity.Send("\r\n&RThere's no exit to the %s.&d\r\n", dir)
+			return
+		}
+		flags := room.GetExitFlags(dir)
+		if flags == nil {
+			flags = &RoomExitFlag{}
+			room.SetExitFlags(dir, flags)
+		}
+		value := strings.TrimSpace(strings.Join(args[3:], " "))
+		switch args[2] {
+		case "name":
+			flags.Name = value
+		case "hidden":
+			flags.Hidden = !flags.Hidden
+		case "keypad":
+			flags.Keypad = !flags.Keypad
+		case "difficulty":
+			d, err := strconv.Atoi(value)
+			if err != nil || d < 0 || d > 100 {
+				entity.Send("\r\n&RDifficulty is 0 (can't be picked) to 100.&d\r\n")
+				return
+			}
+			flags.Difficulty = uint(d)
+		case "key":
+			k, err := strconv.Atoi(value)
+			if err != nil || k < 0 {
+				entity.Send("\r\n&RKey is an item vnum, 0 for none.&d\r\n")
+				return
+			}
+			flags.Key = uint(k)
+		default:
+			entity.Send("\r\n&RField invalid.&d\r\n")
+			return
+		}
 	default:
 		entity.Send("\r\n&RField invalid.&d\r\n")
 		return
 	}
 	// Set the data on the AreaRoom []Rooms slice so that when the area is saved, the changes to the room are too.
-	for i, r := range room.Area.Rooms {
-		if r.Id == room.Id {
-			room.Area.Rooms[i] = *room
-		}
-	}
+	room_update(room)
 	entity.Send("\r\n&YSet. Ok.&d\r\n")
 }
 
@@ -290,25 +868,11 @@ func do_room_make_exit(entity Entity, args ...string) {
 		return
 	}
 	if vnum == 0 {
-		to_room := DB().GetRoom(room.Exits[dir], entity.ShipId())
-		delete(room.Exits, dir)
-		delete(to_room.Exits, direction_reverse(dir))
-		if room.ExitFlags != nil {
-			delete(room.ExitFlags, dir)
-		}
-		if to_room.ExitFlags != nil {
-			delete(to_room.ExitFlags, direction_reverse(dir))
-		}
-		for i, r := range room.Area.Rooms {
-			if r.Id == room.Id {
-				room.Area.Rooms[i] = *room
-			}
-		}
-		for i, r := range to_room.Area.Rooms {
-			if r.Id == to_room.Id {
-				to_room.Area.Rooms[i] = *to_room
-			}
+		if room.GetExit(dir) == nil {
+			entity.Send("\r\n&RThere's no exit to the %s.&d\r\n", dir)
+			return
 		}
+		room_unlink(room, dir)
 		entity.Send("\r\n&YExit. Ok&d\r\n")
 		return
 	}
@@ -322,36 +886,10 @@ func do_room_make_exit(entity Entity, args ...string) {
 		entity.Send("\r\n&RFATAL! Rooms are not in the same area!!!&d\r\n")
 		return
 	}
-	room.Exits[dir] = to_room.Id
-	to_room.Exits[direction_reverse(dir)] = room.Id
-	if len(args) == 3 {
-		if args[2] == "1" {
-			if room.ExitFlags == nil {
-				room.ExitFlags = make(map[string]*RoomExitFlag)
-			}
-			if to_room.ExitFlags == nil {
-				to_room.ExitFlags = make(map[string]*RoomExitFlag)
-			}
-			room.ExitFlags[dir] = &RoomExitFlag{
-				Closed: true,
-			}
-			to_room.ExitFlags[direction_reverse(dir)] = &RoomExitFlag{
-				Closed: true,
-			}
-		}
-	}
-	for i, r := range room.Area.Rooms {
-		if r.Id == room.Id {
-			// copy over the DB.[]rooms room back to the Area's []Rooms
-			room.Area.Rooms[i] = *room
-		}
-	}
-	for i, r := range to_room.Area.Rooms {
-		if r.Id == to_room.Id {
-			// copy over the DB.[]rooms room back to the Area's []Rooms
-			to_room.Area.Rooms[i] = *to_room
-		}
-	}
+	room_link(room, dir, to_room, len(args) == 3 && args[2] == "1")
+	// copy over the DB.[]rooms room back to the Area's []Rooms
+	room_update(room)
+	room_update(to_room)
 	entity.Send("\r\n&YExit. Ok.&d\r\n")
 }
 
@@ -373,9 +911,8 @@ func do_room_remove(entity Entity, args ...string) {
 		entity.Send("\r\n&RRoom not found in your area.&d\r\n")
 		return
 	}
-	for dir, rId := range room.Exits {
-		eroom := DB().GetRoom(rId, entity.ShipId())
-		delete(eroom.Exits, direction_reverse(dir))
+	for _, exit := range room.GetExits() {
+		room_unlink(room, exit.GetDirection())
 	}
 	room.Name = "A void"
 	room.Desc = "Somewhere in the void of space."
@@ -384,16 +921,7 @@ func do_room_remove(entity Entity, args ...string) {
 	room.Flags = make([]string, 0)
 	room.Items = make([]Item, 0)
 	room.RoomProgs = make(map[string]string)
-	if room.ship > 0 {
-		ship := DB().GetShip(room.ship)
-		ship.GetData().Rooms[room.Id] = room
-	} else {
-		for i, r := range room.Area.Rooms {
-			if r.Id == room.Id {
-				room.Area.Rooms[i] = *room
-			}
-		}
-	}
+	room_update(room)
 	entity.Send("\r\n&YRemove. Ok.&d\r\n")
 
 }
@@ -432,9 +960,14 @@ func do_item_create(entity Entity, args ...string) {
 		}
 	}
 	keywords := strings.Split(strings.Join(words, " "), " ")
+	id := DB().GetNextItemVnum(room.Area)
+	if id == 0 {
+		entity.Send("\r\n&RArea &W%s&R has no free item vnums, see &Wvnums&R and &Waset itemvnums&R.&d\r\n", room.Area.Name)
+		return
+	}
 	item := new(ItemData)
-	item.Id = DB().GetNextItemVnum()
-	item.OId = DB().GetNextItemVnum()
+	item.Id = id
+	item.OId = id
 	item.Name = itemname
 	item.Keywords = make([]string, 0)
 	item.Keywords = append(item.Keywords, keywords...)
@@ -446,8 +979,11 @@ func do_item_create(entity Entity, args ...string) {
 		item.Items = make([]Item, 0)
 	}
 	item.Filename = sprintf("data/items/%s/%s.yml", strings.ToLower(strings.ReplaceAll(room.Area.Name, " ", "")), strings.ToLower(filename))
-	DB().SaveItem(item)
-	DB().LoadItem(item.Filename)
+	if err := DB().SaveItem(item); err != nil {
+		entity.Send("\r\n&RUnable to save object: &W%s&d\r\n", err.Error())
+		return
+	}
+	DB().AddItem(item)
 	entity.Send("\r\n&YObject Create. Ok.&d\r\n")
 	room.AddItem(item_clone(item))
 }
@@ -517,15 +1053,32 @@ func do_item_set(entity Entity, args ...string) {
 	case "ac":
 		value, _ := strconv.Atoi(args[2])
 		i.AC = value
+	case "extra":
+		// oset <item> extra inscription,writing The blade reads... or just the keywords to remove it.
+		keywords := strings.Split(strings.ToLower(args[2]), ",")
+		i.Extras = extra_desc_set(i.Extras, keywords, consolify(strings.TrimSpace(strings.Join(args[3:], " "))))
+	case "protects":
+		// oset <item> protects breath toggles it.
+		if !is_protect(args[2]) {
+			entity.Send("\r\n&RInvalid protection, one of %s.&d\r\n", strings.Join(protect_list, ", "))
+			return
+		}
+		if slice_contains_string(i.Protects, args[2]) {
+			i.Protects = slice_remove_string(i.Protects, args[2])
+		} else {
+			i.Protects = append(i.Protects, args[2])
+		}
 	default:
 		entity.Send("\r\nSyntax: oset <item> <field> <value>\r\n")
 		entity.Send("--------------------------------------------\r\n")
 		entity.Send("Fields are:\r\n")
-		entity.Send("name, desc, type, keywords, value, wearLoc, weaponType, weight, ac\r\n")
+		entity.Send("name, desc, type, keywords, value, wearLoc, weaponType, weight, ac, extra, protects\r\n")
 		return
 	}
 	i.Id = i.OId
-	DB().SaveItem(i)
+	if err := DB().SaveItem(i); err != nil {
+		entity.Send("\r\n&RUnable to save object: &W%s&d\r\n", err.Error())
+	}
 	DB().items[i.Id] = i
 	entity.Send("\r\nObject Set. Ok.&d\r\n")
 }
@@ -538,19 +1091,18 @@ func do_item_remove(entity Entity, args ...string) {
 	room := entity.GetRoom()
 	item := room.FindItem(args[0])
 	if item == nil {
-		entity.Send("\r\n&RUnable to find item.")
+		entity.Send("\r\n&RUnable to find item.&d\r\n")
+		return
 	}
 	item_id := item.GetData().OId
 	room.RemoveItem(item)
 	delete(DB().items, item_id)
 	for _, a := range DB().areas {
-		for i, isp := range a.Items {
-			if isp.Item == item_id {
-				// remove the mobspawn that has this mob listed
-				ret := make([]ItemSpawn, 0)
-				ret = append(ret, a.Items[:i]...)
-				ret = append(ret, a.Items[i+1:]...)
-				a.Items = ret
+		spawns := a.GetObjectSpawns()
+		for i := len(spawns) - 1; i >= 0; i-- {
+			if spawns[i].Item == item_id {
+				// remove the item spawn that has this item listed
+				a.DeleteObjectSpawn(uint(i))
 			}
 		}
 	}
@@ -572,12 +1124,20 @@ func do_item_spawn(entity Entity, args ...string) {
 		return
 	} else {
 		room := entity.GetRoom()
-		room.Area.Items = append(room.Area.Items, ItemSpawn{
-			Item: item.OId,
-			Room: room.Id,
-		})
+		area := room_reset_area(room)
+		if area == nil {
+			entity.Send("\r\n&RNot in an area!&d\r\n")
+			return
+		}
+		index := len(area.GetObjectSpawns())
+		area.SetObjectSpawn(uint(index), item.Id, room.Id)
+		area.Items[index].Ship = room.ship
+		if err := area_reset_item(&area.Items[index]); err != nil {
+			entity.Send("\r\n&R%s&d\r\n", err.Error())
+			return
+		}
+		entity.Send("\r\n&YObject Spawn &W%d&Y. Ok. See &Wresets&Y for chance, limits and contents.&d\r\n", index)
 	}
-	entity.Send("\r\n&YObject Spawn. Ok.&d\r\n")
 }
 
 func do_item_stat(entity Entity, args ...string) {
@@ -616,11 +1176,17 @@ func do_item_stat(entity Entity, args ...string) {
 		}
 		entity.Send("&G IsWearable: [%s]  Wear Location: &W%s&d\r\n", isWearable, wearLocation)
 		entity.Send("&GIsContainer: [%s]&d\r\n", isContainer)
+		if len(i.Protects) > 0 {
+			entity.Send("&G   Protects: &W%s&d\r\n", strings.Join(i.Protects, ", "))
+		}
 		if i.Type == ITEM_TYPE_CONTAINER {
 			for _, i := range i.Items {
-				entity.Send("&Y[&W%d&Y]&w%s&d\r\n", i.GetData().Name)
+				entity.Send("&Y[&W%d&Y]&w%s&d\r\n", i.GetData().Id, i.GetData().Name)
 			}
 		}
+		for _, e := range i.Extras {
+			entity.Send("&G      Extra: &W%s&d\r\n", strings.Join(e.Keywords, ", "))
+		}
 
 	}
 }
@@ -632,13 +1198,18 @@ func do_item_find(entity Entity, args ...string) {
 func do_room_find(entity Entity, args ...string) {
 	if len(args) == 0 {
 		room := entity.GetRoom()
+		area := room.GetArea()
+		if area == nil {
+			entity.Send("\r\n&RNot in an area!&d\r\n")
+			return
+		}
 		entity.Send("\r\n%s\r\n", MakeTitle("Rooms", ANSI_TITLE_STYLE_SYSTEM, ANSI_TITLE_ALIGNMENT_LEFT))
 		rlist := make([]string, 0)
-		for _, r := range room.Area.Rooms {
-			if r.Name == "A void" {
+		for _, r := range area.GetRooms() {
+			if r.GetName() == "A void" {
 				continue
 			}
-			n := sprintf("&Y[&W%d&Y]&d%-26s", r.Id, tstring(r.Name, 23))
+			n := sprintf("&Y[&W%d&Y]&d%-26s", r.GetId(), tstring(r.GetName(), 23))
 			rlist = append(rlist, n)
 		}
 		p1 := (len(rlist) / 3) + 1
@@ -684,8 +1255,13 @@ func do_mob_create(entity Entity, args ...string) {
 		filename = strings.TrimSuffix(filename, ".yaml")
 		filename = strings.TrimSuffix(filename, ".yml")
 	}
+	id := DB().GetNextMobVnum(room.Area)
+	if id == 0 {
+		entity.Send("\r\n&RArea &W%s&R has no free mob vnums, see &Wvnums&R and &Waset mobvnums&R.&d\r\n", room.Area.Name)
+		return
+	}
 	mob := new(CharData)
-	mob.Id = DB().GetNextMobVnum()
+	mob.Id = id
 	mob.OId = mob.Id
 	mob.Name = strings.TrimSpace(strings.Join(args[1:], " "))
 	mob.Desc = "An unfinished creature stands here staring blankly off into the distance."
@@ -726,8 +1302,11 @@ func do_mob_create(entity Entity, args ...string) {
 	mob.Room = room.Id
 	mob.Ship = room.ship
 
-	DB().SaveMob(mob)
-	DB().LoadMob(mob.Filename)
+	if err := DB().SaveMob(mob); err != nil {
+		entity.Send("\r\n&RUnable to save mob: &W%s&d\r\n", err.Error())
+		return
+	}
+	DB().AddMob(mob)
 	DB().SpawnEntity(mob)
 	entity.Send("\r\n&YMob Create. Ok.&d\r\n")
 }
@@ -748,13 +1327,20 @@ func do_mob_spawn(entity Entity, args ...string) {
 		return
 	} else {
 		room := entity.GetRoom()
-		room.Area.Mobs = append(room.Area.Mobs, MobSpawn{
-			Mob:  mob.OId,
-			Room: room.Id,
-		})
+		area := room_reset_area(room)
+		if area == nil {
+			entity.Send("\r\n&RNot in an area!&d\r\n")
+			return
+		}
+		index := len(area.GetMobSpawns())
+		area.SetMobSpawn(uint(index), mob.Id, room.Id)
+		area.Mobs[index].Ship = room.ship
+		if err := area_reset_mob(&area.Mobs[index]); err != nil {
+			entity.Send("\r\n&R%s&d\r\n", err.Error())
+			return
+		}
+		entity.Send("\r\n&YMob Spawn &W%d&Y. Ok. See &Wresets&Y for chance, limits and equipment.&d\r\n", index)
 	}
-	DB().SpawnEntity(mob)
-	entity.Send("\r\n&YMob Spawn. Ok.&d\r\n")
 }
 func do_mob_set(entity Entity, args ...string) {
 	if entity == nil {
@@ -959,8 +1545,11 @@ func do_mob_set(entity Entity, args ...string) {
 		entity.Send("languages, speaking, brain\r\n")
 	}
 	tch.Id = tch.OId // make it an original mob. Not a clone.
-	DB().SaveMob(tch)
-	DB().LoadMob(tch.Filename)
+	if err := DB().SaveMob(tch); err != nil {
+		entity.Send("\r\n&RUnable to save mob: &W%s&d\r\n", err.Error())
+		return
+	}
+	DB().AddMob(tch)
 	entity.Send("\r\n&YMob Set. Ok.&d\r\n")
 }
 
@@ -1005,18 +1594,19 @@ func do_mob_remove(entity Entity, args ...string) {
 		return
 	}
 	tch := target.GetCharData()
+	mob := tch
+	if proto, ok := DB().mobs[tch.OId]; ok {
+		mob = proto
+	}
 	DB().RemoveEntity(target, false)
-	delete(DB().mobs, tch.OId)
-	err := os.Remove(tch.Filename)
-	ErrorCheck(err)
+	delete(DB().mobs, mob.Id)
+	ErrorCheck(DB().DeleteMob(mob))
 	for _, a := range DB().areas {
-		for i, msp := range a.Mobs {
-			if msp.entity == target || msp.Mob == tch.OId {
+		spawns := a.GetMobSpawns()
+		for i := len(spawns) - 1; i >= 0; i-- {
+			if spawns[i].entity == target || spawns[i].Mob == mob.Id {
 				// remove the mobspawn that has this mob listed
-				ret := make([]MobSpawn, 0)
-				ret = append(ret, a.Mobs[:i]...)
-				ret = append(ret, a.Mobs[i+1:]...)
-				a.Mobs = ret
+				a.DeleteMobSpawn(uint(i))
 			}
 		}
 	}
@@ -1117,14 +1707,133 @@ func do_transfer(entity Entity, args ...string) {
 		entity.Send("\r\n&RUnable to parse room_id!&d\r\n")
 		return
 	}
+	to_room := DB().GetRoom(uint(room_id), 0)
+	if to_room == nil {
+		entity.Send("\r\n&RThere's no room %d!&d\r\n", room_id)
+		return
+	}
 	room := target.GetRoom()
+	// immortals go where they like, the flags are for mob progs.
+	if !entity_is_immortal(entity) {
+		if room.IsFlagged(ROOM_FLAG_NO_RECALL) {
+			entity.Send("\r\n&R%s can't be transferred out of there.&d\r\n", target.GetCharData().Name)
+			return
+		}
+		if room_is_full(to_room) {
+			entity.Send("\r\n&RRoom %d is private and full.&d\r\n", room_id)
+			return
+		}
+	}
 	room.SendToOthers(target, sprintf("\r\n%s has left.\r\n", target.GetCharData().Name))
 	target.GetCharData().Room = uint(room_id)
-	room = DB().GetRoom(uint(room_id), 0)
-	room.SendToOthers(target, sprintf("\r\n%s has appeared.\r\n", target.GetCharData().Name))
+	to_room.SendToOthers(target, sprintf("\r\n%s has appeared.\r\n", target.GetCharData().Name))
 	target.Send("\r\nYou feel a rush of air as your surroundings quickly change.\r\n")
 }
 
+func do_export(entity Entity, args ...string) {
+	if len(args) != 1 {
+		entity.Send("\r\nSyntax: export <area|all>\r\n")
+		entity.Send("-----------------------------------------------------------------\r\n")
+		entity.Send("Writes a Graphviz .dot and an .svg map of the area, or the whole\r\n")
+		entity.Send("galaxy, into the export folder.\r\n")
+		return
+	}
+	mobs, items := export_live_names()
+	files, err := export_world(DB().GetAreas(), mobs, items, args[0], "export")
+	if err != nil {
+		entity.Send("\r\n&RUnable to export: &W%s&d\r\n", err.Error())
+		return
+	}
+	for _, f := range files {
+		entity.Send("\r\n&YWrote &W%s&d", f)
+	}
+	entity.Send("\r\n")
+}
+
+func do_goto(entity Entity, args ...string) {
+	if len(args) == 0 {
+		entity.Send("\r\n&RGoto where?&d\r\nSyntax: goto <room_id|player>\r\n")
+		return
+	}
+	ch := entity.GetCharData()
+	var room *RoomData
+	if vnum, err := strconv.Atoi(args[0]); err == nil {
+		room = DB().GetRoom(uint(vnum), ch.Ship)
+		if room == nil {
+			room = DB().GetRoom(uint(vnum), 0)
+		}
+	} else if target := DB().GetPlayerEntityByName(args[0]); target != nil {
+		room = DB().GetRoom(target.RoomId(), target.ShipId())
+	}
+	if room == nil {
+		entity.Send("\r\n&RNo such room or player.&d\r\n")
+		return
+	}
+	// show the way there for builders checking their areas are connected.
+	from := DB().GetRoom(ch.Room, ch.Ship)
+	if from == nil {
+		from = room
+	}
+	if from.ship == room.ship {
+		if path := path_find_room(entity, from, room.Id); path == nil {
+			entity.Send("\r\n&YThere's no way to walk there from here.&d\r\n")
+		} else if len(path) > 0 {
+			entity.Send("\r\n&YThe way there is %s.&d\r\n", path_string(path))
+		}
+	}
+	from.SendToOthers(entity, sprintf("\r\n%s has left.\r\n", ch.Name))
+	ch.Room = room.Id
+	ch.Ship = room.ship
+	room.SendToOthers(entity, sprintf("\r\n%s has appeared.\r\n", ch.Name))
+	do_look(entity)
+}
+
+// do_settime sets the galactic clock. settime 14, settime 6 120 4aby, or settime +12 to move it on.
+func do_settime(entity Entity, args ...string) {
+	if len(args) == 0 || len(args) > 3 {
+		entity.Send("\r\nSyntax: settime <hour> [day] [year], or settime +<hours>\r\n")
+		entity.Send("&cIt is &Y%s&d\r\n", GetGameTime())
+		return
+	}
+	now := GetGameTime()
+	if strings.HasPrefix(args[0], "+") {
+		hours, err := strconv.Atoi(args[0][1:])
+		if err != nil || hours < 1 {
+			entity.Send("\r\n&RThat's not a number of hours.&d\r\n")
+			return
+		}
+		SetGameTime(now + GameTime(hours))
+	} else {
+		hour, day, year := now.Hour(), now.Day(), now.Year()
+		var err error
+		if hour, err = strconv.Atoi(args[0]); err != nil || hour < 0 || hour >= GST_HOURS_PER_DAY {
+			entity.Send("\r\n&RThe hour is from 0 to %d.&d\r\n", GST_HOURS_PER_DAY-1)
+			return
+		}
+		if len(args) > 1 {
+			if day, err = strconv.Atoi(args[1]); err != nil || day < 1 || day > GST_DAYS_PER_YEAR {
+				entity.Send("\r\n&RThe day is from 1 to %d.&d\r\n", GST_DAYS_PER_YEAR)
+				return
+			}
+		}
+		if len(args) > 2 {
+			// 4, 4aby, -19 or 19bby
+			y := strings.ToLower(args[2])
+			bby := strings.HasSuffix(y, "bby")
+			if year, err = strconv.Atoi(strings.TrimSuffix(strings.TrimSuffix(y, "bby"), "aby")); err != nil {
+				entity.Send("\r\n&RThe year is a number of years ABY, or BBY like 19bby.&d\r\n")
+				return
+			}
+			if bby {
+				year = -year
+			}
+		}
+		SetGameTime(game_time_at(hour, day, year))
+	}
+	log.Printf("ADMIN (SETTIME): %s set the time to %s.", entity.GetCharData().Name, GetGameTime())
+	entity.Send("\r\n&YIt is now &W%s&Y. Ok.&d\r\n", GetGameTime())
+}
+
 func do_advance(entity Entity, args ...string) {
 	if entity == nil {
 		return
@@ -1174,28 +1883,26 @@ func do_dig(entity Entity, args ...string) {
 		return
 	}
 	player := entity.(*PlayerProfile)
-	if player.Priv != 100 {
+	if player.Priv < 100 {
 		entity.Send("\r\n&ROnly Immortals can dig, dig?&d\r\n")
+		return
 	}
 	db := DB()
 	room := player.GetRoom()
 	dir := get_direction_string(strings.ToLower(args[0]))
-	if _, ok := room.Exits[dir]; ok {
+	if room.GetExit(dir) != nil {
 		entity.Send("\r\n&RRoom already exists in that direction!&d\r\n")
 	} else {
-		lastVnum := uint(0)
-		for _, r := range room.Area.Rooms {
-			if r.Id > lastVnum {
-				lastVnum = r.Id
-			}
-		}
 		next_id := db.GetNextRoomVnum(room.Id, room.ship)
 		if next_id == 0 {
+			if room.ship == 0 && room.Area != nil {
+				entity.Send("\r\n&RArea &W%s&R has no free room vnums, see &Wvnums&R and &Waset roomvnums&R.&d\r\n", room.Area.Name)
+				return
+			}
 			entity.Send("\r\n&RUnable to determine next room vnum.&d\r\n")
 			return
 		}
 
-		room.Exits[dir] = next_id
 		next_room := db.GetRoom(next_id, room.ship)
 
 		if next_room == nil {
@@ -1213,26 +1920,19 @@ func do_dig(entity Entity, args ...string) {
 		}
 
 		next_room.Name = strings.TrimSpace(strings.Join(args[1:], " "))
-		next_room.Exits[direction_reverse(dir)] = room.Id
-		if next_room.ship > 0 {
-			ship := db.GetShip(next_room.ship)
-			s := ship.GetData()
-			s.Rooms[next_id] = next_room
-		} else {
-			db.SetRoom(next_id, next_room)
-			for i, r := range room.Area.Rooms {
-				if r.Id == next_room.Id {
-					room.Area.Rooms[i] = *next_room
-				}
-			}
-		}
+		room_link(room, dir, next_room, false)
+		room_update(next_room)
+		room_update(room)
 		entity.Send("\r\n&GDug a room to the %s&d\r\n", dir)
 	}
 }
 
 func do_ship_create(entity Entity, args ...string) {
-	if len(args) < 1 {
+	if len(args) < 2 {
 		entity.Send("\r\nSyntax: screate <ship_type> <name>\r\n")
+		entity.Send("-----------------------------------------------------------------\r\n")
+		entity.Send("Spawns a new ship of ship_type here, making a bare prototype for\r\n")
+		entity.Send("the type first if there isn't one.\r\n")
 		return
 	}
 	room := entity.GetRoom()
@@ -1240,41 +1940,59 @@ func do_ship_create(entity Entity, args ...string) {
 		entity.Send("\r\n&RShips can only be created in spaceports, shipyards, and hangars.&d\r\n")
 		return
 	}
-	ship_type := args[1]
-	id := DB().GetNextShipVnum()
-	ship := &ShipData{
-		Id:            id,
-		OId:           id,
-		Name:          strings.Join(args[2:], " "),
-		Desc:          "A prototype ship",
-		Type:          ship_type,
-		LocationId:    entity.GetRoom().Id,
-		CurrentSystem: "Somewhere",
-		ShipyardId:    entity.GetRoom().Id,
-		Rooms:         make(map[uint]*RoomData),
-		Owner:         entity.GetCharData().Name,
-		Modules:       make(map[string]uint),
-		Position:      []float32{0.0, 0.0},
-		HighSlots:     make([]*ItemData, 0),
-		LowSlots:      make([]*ItemData, 0),
-		Cockpit:       1,
-		Ramp:          1,
-		EngineRoom:    1,
-		CargoRoom:     1,
-		Blueprint:     0,
-		MaxSpeed:      1,
-		Hp:            []uint{100, 100},
-		Sp:            []uint{0, 0},
-	}
-	ship.Rooms[1] = &RoomData{
-		Id:   1,
-		Name: "A prototype cockpit",
-		Desc: "A stripped down prototype cockpit, barely able to maintain flight.",
-		ship: ship.Id,
-	}
-	DB().SaveShip(ship)
-	DB().LoadShip(sprintf("data/ships/%s.yml", ship.Name))
-	DB().SpawnShip(ship)
+	ship_type := args[0]
+	name := strings.Join(args[1:], " ")
+	if !DB().ShipNameAvailable(name) {
+		entity.Send("\r\n&RThere's already a ship called %s.&d\r\n", name)
+		return
+	}
+	proto := DB().GetShipPrototype(ship_type)
+	if proto == nil {
+		id := DB().GetNextShipVnum()
+		proto = &ShipData{
+			Id:            id,
+			OId:           id,
+			Name:          name,
+			Desc:          "A prototype ship",
+			Type:          ship_type,
+			LocationId:    room.Id,
+			CurrentSystem: "Somewhere",
+			ShipyardId:    room.Id,
+			Rooms:         make(map[uint]*RoomData),
+			Owner:         entity.GetCharData().Name,
+			Modules:       make(map[string]uint),
+			Position:      []float32{0.0, 0.0},
+			HighSlots:     make([]*ItemData, 0),
+			LowSlots:      make([]*ItemData, 0),
+			Cockpit:       1,
+			Ramp:          1,
+			EngineRoom:    1,
+			CargoRoom:     1,
+			Blueprint:     0,
+			MaxSpeed:      1,
+			Hp:            []uint{100, 100},
+			Sp:            []uint{0, 0},
+		}
+		proto.Rooms[1] = &RoomData{
+			Id:   1,
+			Name: "A prototype cockpit",
+			Desc: "A stripped down prototype cockpit, barely able to maintain flight.",
+		}
+		if err := DB().store.SaveShipPrototype(proto); err != nil {
+			entity.Send("\r\n&RUnable to save ship prototype: &W%s&d\r\n", err.Error())
+			return
+		}
+		DB().AddShipPrototype(proto)
+	}
+	ship := DB().SpawnShip(proto).GetData()
+	ship.Name = name
+	ship.LocationId = room.Id
+	ship.ShipyardId = room.Id
+	ship.Owner = entity.GetCharData().Name
+	if err := DB().SaveShip(ship); err != nil {
+		entity.Send("\r\n&RUnable to save ship: &W%s&d\r\n", err.Error())
+		return
+	}
 
 	entity.Send("\r\n&YShip Create. Ok.&d\r\n")
 
@@ -1309,12 +2027,10 @@ func do_ship_remove(entity Entity, args ...string) {
 		return
 	}
 	DB().RemoveShip(ship)
-	e := os.Remove(sprintf("data/ships/%s.yml", strings.ToLower(strings.ReplaceAll(ship.GetData().Name, " ", "_"))))
-	ErrorCheck(e)
+	ErrorCheck(DB().DeleteShip(ship))
 	if prototype {
+		ErrorCheck(DB().DeleteShipPrototype(ship))
 		DB().RemoveShipPrototype(ship)
-		e = os.Remove(sprintf("data/ships/prototypes/%s.yml", strings.ToLower(strings.ReplaceAll(ship.GetData().Name, " ", "_"))))
-		ErrorCheck(e)
 	}
 	entity.Send("\r\n&YRemove Ship. Ok.&d\r\n")
 }
@@ -1326,3 +2042,172 @@ func do_ship_set(entity Entity, args ...string) {
 func do_ship_stat(entity Entity, args ...string) {
 
 }
+
+func do_reload(entity Entity, args ...string) {
+	if entity == nil {
+		return
+	}
+	if len(args) != 1 {
+		entity.Send("\r\nSyntax: reload <commands|socials>\r\n")
+		return
+	}
+	switch strings.ToLower(args[0]) {
+	case "commands":
+		commands, err := commands_read("data/sys/commands.yml")
+		if err != nil {
+			entity.Send("\r\n&RUnable to reload commands: &W%s&d\r\n", err.Error())
+			return
+		}
+		for _, p := range commands_validate(commands) {
+			entity.Send("&Y%s&d\r\n", p)
+		}
+		commands_swap(commands)
+		log.Printf("ADMIN (RELOAD): %s reloaded %d commands.", entity.GetCharData().Name, len(commands))
+		entity.Send("\r\n&YReloaded &W%d&Y commands. Ok.&d\r\n", len(commands))
+	case "socials":
+		socials, err := socials_read("data/sys/socials.yml")
+		if err != nil {
+			entity.Send("\r\n&RUnable to reload socials: &W%s&d\r\n", err.Error())
+			return
+		}
+		socials_swap(socials)
+		log.Printf("ADMIN (RELOAD): %s reloaded %d socials.", entity.GetCharData().Name, len(socials))
+		entity.Send("\r\n&YReloaded &W%d&Y socials. Ok.&d\r\n", len(socials))
+	default:
+		entity.Send("\r\nSyntax: reload <commands|socials>\r\n")
+	}
+}
+
+// do_migrate copies the world from one storage backend to another. The running game is saved first
+// so nothing in memory is left behind, switching over is then a database: change in config.yml and a reboot.
+func do_migrate(entity Entity, args ...string) {
+	if entity == nil {
+		return
+	}
+	if len(args) != 2 || strings.EqualFold(args[0], args[1]) {
+		entity.Send("\r\nSyntax: migrate <yaml|sqlite> <yaml|sqlite>\r\n")
+		entity.Send("&dCurrently using &W%s&d.\r\n", DB().store.Name())
+		return
+	}
+	from, err := database_open(args[0], DB().db)
+	if err != nil {
+		entity.Send("\r\n&R%s&d\r\n", err.Error())
+		return
+	}
+	to, err := database_open(args[1], DB().db)
+	if err != nil {
+		entity.Send("\r\n&R%s&d\r\n", err.Error())
+		return
+	}
+	DB().Save()
+	counts, err := database_migrate(from, to)
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		entity.Send("&G%-16s &W%d&d\r\n", k, counts[k])
+	}
+	if err != nil {
+		entity.Send("\r\n&RMigration failed: &W%s&d\r\n", err.Error())
+		return
+	}
+	log.Printf("ADMIN (MIGRATE): %s migrated the database from %s to %s.", entity.GetCharData().Name, from.Name(), to.Name())
+	entity.Send("\r\n&YMigrated from &W%s&Y to &W%s&Y. Ok.&d\r\n", from.Name(), to.Name())
+	entity.Send("&YSet &Wdatabase: %s&Y in config.yml and reboot to switch over.&d\r\n", to.Name())
+}
+
+func do_snoop(entity Entity, args ...string) {
+	if entity == nil || !entity.IsPlayer() {
+		return
+	}
+	player := entity.(*PlayerProfile)
+	if player.Client == nil {
+		return
+	}
+	if len(args) == 0 || strings.EqualFold(args[0], player.Char.Name) || strings.EqualFold(args[0], "self") {
+		// cancel all snoops
+		db := DB()
+		db.Lock()
+		for _, c := range db.clients {
+			if c != nil && c.GetSnooper() == player.Client {
+				c.SetSnooper(nil)
+			}
+		}
+		db.Unlock()
+		entity.Send("\r\n&YCancelling all snoops.&d\r\n")
+		return
+	}
+	target := DB().GetPlayerEntityByName(args[0])
+	if target == nil {
+		entity.Send("\r\n&RUnable to find player %s.&d\r\n", args[0])
+		return
+	}
+	victim := target.(*PlayerProfile)
+	if victim.Client == nil {
+		entity.Send("\r\n&R%s has no connection to snoop.&d\r\n", victim.Char.Name)
+		return
+	}
+	if victim.Priv > player.Priv {
+		entity.Send("\r\n&RYou failed.&d\r\n")
+		return
+	}
+	if victim.Client.GetSnooper() != nil {
+		entity.Send("\r\n&R%s is already being snooped.&d\r\n", victim.Char.Name)
+		return
+	}
+	// don't let a snoop loop back on itself.
+	for s := player.Client.GetSnooper(); s != nil; s = s.GetSnooper() {
+		if s == victim.Client {
+			entity.Send("\r\n&RNo snoop loops.&d\r\n")
+			return
+		}
+	}
+	victim.Client.SetSnooper(player.Client)
+	log.Printf("ADMIN (SNOOP): %s is snooping %s.", player.Char.Name, victim.Char.Name)
+	entity.Send("\r\n&YSnooping &W%s&Y. Ok.&d\r\n", victim.Char.Name)
+}
+
+func do_log(entity Entity, args ...string) {
+	if entity == nil {
+		return
+	}
+	if len(args) != 1 {
+		entity.Send("\r\nSyntax: log <player>\r\n")
+		return
+	}
+	target := DB().GetPlayerEntityByName(args[0])
+	if target == nil {
+		entity.Send("\r\n&RUnable to find player %s.&d\r\n", args[0])
+		return
+	}
+	player := target.(*PlayerProfile)
+	player.Logged = !player.Logged
+	if player.Logged {
+		log.Printf("ADMIN (LOG): %s turned on logging for %s.", entity.GetCharData().Name, player.Char.Name)
+		entity.Send("\r\n&YLogging &W%s&Y. Ok.&d\r\n", player.Char.Name)
+	} else {
+		log.Printf("ADMIN (LOG): %s turned off logging for %s.", entity.GetCharData().Name, player.Char.Name)
+		entity.Send("\r\n&YNo longer logging &W%s&Y. Ok.&d\r\n", player.Char.Name)
+	}
+}
+
+func do_audit(entity Entity, args ...string) {
+	if entity == nil {
+		return
+	}
+	count := 20
+	if len(args) > 0 {
+		c, err := strconv.Atoi(args[0])
+		if err != nil || c < 1 {
+			entity.Send("\r\nSyntax: audit <count?>\r\n")
+			return
+		}
+		count = c
+	}
+	entity.Send("\r\n%s\r\n", MakeTitle("Audit Log", ANSI_TITLE_STYLE_SYSTEM, ANSI_TITLE_ALIGNMENT_LEFT))
+	for _, a := range audit_recent(count) {
+		entity.Send("&Y%s &W%-12s &G[%d]&d %s\r\n", a.CreatedAt.UTC().Format("2006-01-02 15:04:05"), a.Actor, a.Room, a.Input)
+	}
+}
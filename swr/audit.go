@@ -0,0 +1,61 @@
+/*  Star Wars Role-Playing Mud
+ *  Copyright (C) 2022 @{See Authors}
+ *
+ *  This program is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  This program is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *  GNU General Public License for more details.
+ *
+ *  You should have received a copy of the GNU General Public License
+ *  along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+package swr
+
+import (
+	"gorm.io/gorm"
+)
+
+// AuditLog is an append-only record of a privileged (immortal) command. Rows are only ever created,
+// CreatedAt is when the command was issued.
+type AuditLog struct {
+	gorm.Model
+	ActorID uint   `gorm:"index"`
+	Actor   string `gorm:"index"`
+	Command string
+	Input   string
+	Room    uint
+	Ship    uint
+}
+
+// audit_record writes a privileged command to the audit log.
+func audit_record(entity Entity, com *Command, input string) {
+	ch := entity.GetCharData()
+	entry := &AuditLog{
+		ActorID: ch.Id,
+		Actor:   ch.Name,
+		Command: com.Name,
+		Input:   input,
+		Room:    ch.Room,
+		Ship:    ch.Ship,
+	}
+	db := DB()
+	db.Lock()
+	defer db.Unlock()
+	ErrorCheck(db.db.Create(entry).Error)
+}
+
+// audit_recent returns the latest audit entries, newest first.
+func audit_recent(count int) []AuditLog {
+	ret := make([]AuditLog, 0)
+	db := DB()
+	db.Lock()
+	defer db.Unlock()
+	ErrorCheck(db.db.Order("id desc").Limit(count).Find(&ret).Error)
+	return ret
+}
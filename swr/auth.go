@@ -29,6 +29,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// START_ROOM is where new characters begin, and where anyone whose room is gone ends up.
+const START_ROOM = 100
+
 type Account struct {
 	gorm.Model
 	ID         uint   `gorm:"primarykey"`
@@ -63,10 +66,13 @@ Login:
 		goto Login
 	}
 	sanitized := strings.TrimSpace(strings.ToLower(username))
-	path := fmt.Sprintf("data/accounts/%s/%s.yml", sanitized[0:1], sanitized)
 	log.Printf("Loading player %s", sanitized)
-	if file_exists(path) {
-		player := DB().ReadPlayerData(path)
+	if DB().PlayerExists(sanitized) {
+		player := DB().ReadPlayerData(sanitized)
+		if player == nil {
+			client.Send("\r\n&RUnable to load that player, please contact an immortal.&d\r\n")
+			goto Login
+		}
 		client.Send("\r\n&GPassword:&d ")
 		telnet_disable_local_echo(client)
 		password := client.Read()
@@ -82,8 +88,22 @@ Login:
 			client.Send(Color().ClearScreen())
 			player.LastSeen = time.Now()
 			player.Client = client
-			DB().SavePlayerData(player)
+			ErrorCheck(DB().SavePlayerData(player))
 			room := DB().GetRoom(player.Char.Room, player.Char.Ship)
+			if room == nil {
+				// the ship they were aboard is gone, start them over.
+				player.Char.Room = START_ROOM
+				player.Char.Ship = 0
+				room = DB().GetRoom(player.Char.Room, player.Char.Ship)
+			}
+			if room == nil {
+				log.Printf("Error: start room %d doesn't exist, %s can't log in.", START_ROOM, player.Char.Name)
+				client.Send("\r\n&RUnable to find a room to put you in, please contact an immortal.&d\r\n")
+				goto Login
+			}
+			if player.Char.State == ENTITY_STATE_PILOTING {
+				player.Char.State = ENTITY_STATE_NORMAL
+			}
 			room.SendToRoom(fmt.Sprintf("\r\n&P%s&d has arrived.\r\n", player.Char.Name))
 			// see if player is already in the game...
 			p := DB().GetPlayerEntityByName(player.Char.Name)
@@ -222,7 +242,7 @@ Stats:
 	player.Char = CharData{}
 	player.Char.Id = gen_player_char_id()
 	player.Char.Name = capitalize(name)
-	player.Char.Room = 100
+	player.Char.Room = START_ROOM
 	player.Char.Race = race
 	player.Char.Gender = capitalize(gender)
 	player.Char.Title = fmt.Sprintf("%s the %s", player.Char.Name, player.Char.Race)
@@ -264,7 +284,12 @@ Stats:
 		client.Close()
 		return
 	}
-	DB().SavePlayerData(player)
+	if err := DB().SavePlayerData(player); err != nil {
+		ErrorCheck(err)
+		client.Send("\r\n&RUnable to create your character, please try again later.&d\r\n")
+		client.Close()
+		return
+	}
 	player.Client = client
 	room := DB().GetRoom(player.Char.Room, player.Char.Ship)
 	room.SendToRoom(fmt.Sprintf("\r\n&P%s&d has arrived.\r\n", player.Char.Name))
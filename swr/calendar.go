@@ -0,0 +1,101 @@
+/*  Star Wars Role-Playing Mud
+ *  Copyright (C) 2022 @{See Authors}
+ *
+ *  This program is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  This program is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *  GNU General Public License for more details.
+ *
+ *  You should have received a copy of the GNU General Public License
+ *  along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+package swr
+
+import (
+	"sync/atomic"
+)
+
+// The Galactic Standard Calendar. Standard days are 24 hours, weeks are 5 days and years 368 days.
+const (
+	GST_HOURS_PER_DAY  = 24
+	GST_DAYS_PER_WEEK  = 5
+	GST_DAYS_PER_YEAR  = 368
+	GST_HOURS_PER_YEAR = GST_HOURS_PER_DAY * GST_DAYS_PER_YEAR
+)
+
+// GAME_HOUR is how many real seconds make a standard hour in the game.
+const GAME_HOUR = 60
+
+var gst_weekdays = []string{"Primeday", "Centaxday", "Taungsday", "Zhellday", "Benduday"}
+
+// GameTime is the in-game clock, standard hours since the Battle of Yavin. Before it is negative.
+type GameTime int64
+
+// game_time is the current time, saved with the world state so it carries on after a reboot.
+var game_time int64
+
+// GetGameTime returns the current time on the galactic clock.
+func GetGameTime() GameTime {
+	return GameTime(atomic.LoadInt64(&game_time))
+}
+
+// SetGameTime moves the galactic clock.
+func SetGameTime(t GameTime) {
+	atomic.StoreInt64(&game_time, int64(t))
+}
+
+// calendar_tick moves the clock on an hour, and the weather with it.
+func calendar_tick() {
+	atomic.AddInt64(&game_time, 1)
+	weather_update()
+}
+
+// game_time_at returns the time for the hour of the day, day of the year (from 1) and year.
+func game_time_at(hour int, day int, year int) GameTime {
+	return GameTime(int64(year)*GST_HOURS_PER_YEAR + int64(day-1)*GST_HOURS_PER_DAY + int64(hour))
+}
+
+// floor_mod is the remainder that's never negative, so the years before the battle count the right way.
+func floor_mod(a int64, b int64) int64 {
+	return ((a % b) + b) % b
+}
+
+// Year returns the year, 0 is the year of the Battle of Yavin and negative years are before it.
+func (t GameTime) Year() int {
+	return int((int64(t) - floor_mod(int64(t), GST_HOURS_PER_YEAR)) / GST_HOURS_PER_YEAR)
+}
+
+// Day returns the day of the year, from 1 to GST_DAYS_PER_YEAR.
+func (t GameTime) Day() int {
+	return int(floor_mod(int64(t), GST_HOURS_PER_YEAR)/GST_HOURS_PER_DAY) + 1
+}
+
+// Hour returns the standard hour of the day, from 0 to 23.
+func (t GameTime) Hour() int {
+	return int(floor_mod(int64(t), GST_HOURS_PER_DAY))
+}
+
+// Weekday returns the name of the day of the week.
+func (t GameTime) Weekday() string {
+	days := (int64(t) - floor_mod(int64(t), GST_HOURS_PER_DAY)) / GST_HOURS_PER_DAY
+	return gst_weekdays[floor_mod(days, GST_DAYS_PER_WEEK)]
+}
+
+// Era returns the year the way people say it, "4 ABY" or "19 BBY".
+func (t GameTime) Era() string {
+	year := t.Year()
+	if year < 0 {
+		return sprintf("%d BBY", -year)
+	}
+	return sprintf("%d ABY", year)
+}
+
+func (t GameTime) String() string {
+	return sprintf("%s, day %d of %s, %02d:00 GST", t.Weekday(), t.Day(), t.Era(), t.Hour())
+}
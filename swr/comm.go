@@ -22,6 +22,7 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -60,6 +61,10 @@ var CommandFuncs = map[string]func(Entity, ...string){
 	"do_sit":            do_sit,
 	"do_open":           do_open,
 	"do_close":          do_close,
+	"do_search":         do_search,
+	"do_pick":           do_pick,
+	"do_track":          do_track,
+	"do_travel":         do_travel,
 	"do_get":            do_get,
 	"do_give":           do_give,
 	"do_put":            do_put,
@@ -71,10 +76,20 @@ var CommandFuncs = map[string]func(Entity, ...string){
 	"do_remove":         do_remove,
 	"do_statsys":        do_statsys,
 	"do_commands":       do_commands,
+	"do_socials":        do_socials,
 	"do_time":           do_time,
+	"do_map":            do_map,
 	"do_levels":         do_levels,
 	"do_board_ship":     do_board_ship,
-	"do_leave_Ship":     do_leave_ship,
+	"do_leave_ship":     do_leave_ship,
+	"do_pilot":          do_pilot,
+	"do_launch":         do_launch,
+	"do_land":           do_land,
+	"do_course":         do_course,
+	"do_speed":          do_speed,
+	"do_ship_status":    do_ship_status,
+	"do_calculate":      do_calculate,
+	"do_hyperspace":     do_hyperspace,
 }
 var GMCommandFuncs = map[string]func(Entity, ...string){
 	"do_area_create":    do_area_create,
@@ -82,6 +97,12 @@ var GMCommandFuncs = map[string]func(Entity, ...string){
 	"do_area_remove":    do_area_remove,
 	"do_area_reset":     do_area_reset,
 	"do_area_save":      do_area_save,
+	"do_area_load":      do_area_load,
+	"do_area_reload":    do_area_reload,
+	"do_vnums":          do_vnums,
+	"do_goto":           do_goto,
+	"do_export":         do_export,
+	"do_resets":         do_resets,
 	"do_room_find":      do_room_find,
 	"do_room_remove":    do_room_remove,
 	"do_room_set":       do_room_set,
@@ -105,27 +126,242 @@ var GMCommandFuncs = map[string]func(Entity, ...string){
 	"do_ship_stat":      do_ship_stat,
 	"do_transfer":       do_transfer,
 	"do_advance":        do_advance,
+	"do_settime":        do_settime,
 	"do_dig":            do_dig,
 	"do_editor":         do_editor,
+	"do_snoop":          do_snoop,
+	"do_log":            do_log,
+	"do_audit":          do_audit,
+	"do_migrate":        do_migrate,
 }
 
 var Commands []*Command = make([]*Command, 0)
+var commands_mu sync.RWMutex
+
+// Command log levels. Normal commands are only logged for players with logging turned on (see do_log).
+const (
+	COMMAND_LOG_NEVER  = "never"  // never logged, even when the player is being logged. (passwords)
+	COMMAND_LOG_NORMAL = "normal" // logged when the player is being logged.
+	COMMAND_LOG_ALWAYS = "always" // always logged.
+)
+
+// Positions, from worst to best. A command's Position is the minimum position an entity
+// must be in to use it. Positions are derived from the entity's ENTITY_STATE_*.
+const (
+	POSITION_DEAD        = "dead"
+	POSITION_UNCONSCIOUS = "unconscious"
+	POSITION_SLEEPING    = "sleeping"
+	POSITION_SITTING     = "sitting"
+	POSITION_FIGHTING    = "fighting"
+	POSITION_STANDING    = "standing"
+)
+
+var positions = []string{
+	POSITION_DEAD,
+	POSITION_UNCONSCIOUS,
+	POSITION_SLEEPING,
+	POSITION_SITTING,
+	POSITION_FIGHTING,
+	POSITION_STANDING,
+}
 
 type Command struct {
 	Name     string   `yaml:"name"`
 	Keywords []string `yaml:"keywords,flow"`
 	Level    uint     `yaml:"level"`
 	Func     string   `yaml:"func"`
+	Position string   `yaml:"position,omitempty"` // minimum POSITION_* required, empty is no requirement.
+	Log      string   `yaml:"log,omitempty"`      // COMMAND_LOG_* level, empty is normal.
+}
+
+// do_reload is registered here rather than in GMCommandFuncs because it reloads
+// the command table, which is validated against GMCommandFuncs (an initialization cycle).
+func init() {
+	GMCommandFuncs["do_reload"] = do_reload
 }
 
 func CommandsLoad() {
 	log.Printf("Loading commands list.")
-	fp, err := os.ReadFile("data/sys/commands.yml")
-	ErrorCheck(err)
-	err = yaml.Unmarshal(fp, &Commands)
+	commands, err := commands_read("data/sys/commands.yml")
 	ErrorCheck(err)
-	log.Printf("%d commands successfully loaded.", len(Commands))
+	if err != nil {
+		return
+	}
+	commands_swap(commands)
+	log.Printf("%d commands successfully loaded.", len(commands))
+}
+
+// commands_read reads and validates a commands file. Commands mapped to functions that
+// don't exist are reported and left out of the returned list.
+func commands_read(path string) ([]*Command, error) {
+	fp, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	commands := make([]*Command, 0)
+	err = yaml.Unmarshal(fp, &commands)
+	if err != nil {
+		return nil, err
+	}
+	problems := commands_validate(commands)
+	for _, p := range problems {
+		log.Printf("BUG: %s: %s", path, p)
+	}
+	ret := make([]*Command, 0, len(commands))
+	for _, com := range commands {
+		if command_func_exists(com.Func) {
+			ret = append(ret, com)
+		}
+	}
+	return ret, nil
 }
+
+// commands_validate checks the command table for unknown functions, unknown options,
+// duplicate names and duplicate keywords. Returns a list of the problems found.
+func commands_validate(commands []*Command) []string {
+	problems := make([]string, 0)
+	names := make(map[string]bool)
+	keywords := make(map[string]string)
+	for _, com := range commands {
+		if !command_func_exists(com.Func) {
+			problems = append(problems, sprintf("command %s maps to unknown function %s", com.Name, com.Func))
+		}
+		if names[com.Name] {
+			problems = append(problems, sprintf("command %s is defined more than once", com.Name))
+		}
+		names[com.Name] = true
+		for _, k := range com.Keywords {
+			if other, ok := keywords[k]; ok && other != com.Name {
+				problems = append(problems, sprintf("keyword %s is used by both %s and %s", k, other, com.Name))
+			}
+			keywords[k] = com.Name
+		}
+		if com.Position != "" && position_rank(com.Position) < 0 {
+			problems = append(problems, sprintf("command %s has unknown position %s", com.Name, com.Position))
+		}
+		switch com.Log {
+		case "", COMMAND_LOG_NEVER, COMMAND_LOG_NORMAL, COMMAND_LOG_ALWAYS:
+		default:
+			problems = append(problems, sprintf("command %s has unknown log level %s", com.Name, com.Log))
+		}
+
+	}
+	return problems
+}
+
+// commands_swap replaces the command table in one go so lookups never see a half loaded table.
+func commands_swap(commands []*Command) {
+	commands_mu.Lock()
+	defer commands_mu.Unlock()
+	Commands = commands
+}
+
+// commands_list returns the current command table.
+func commands_list() []*Command {
+	commands_mu.RLock()
+	defer commands_mu.RUnlock()
+	return Commands
+}
+
+func command_func_exists(name string) bool {
+	if _, ok := CommandFuncs[name]; ok {
+		return true
+	}
+	_, ok := GMCommandFuncs[name]
+	return ok
+}
+
+// command_find_func returns the first command mapped to the function name, nil if there isn't one.
+func command_find_func(name string) *Command {
+	for _, com := range commands_list() {
+		if com.Func == name {
+			return com
+		}
+	}
+	return nil
+}
+
+// command_check_options enforces the command's position and logging, returns false if the entity can't use the command right now.
+func command_check_options(entity Entity, com *Command, input string) bool {
+	if com == nil {
+		return true
+	}
+	if !command_check_position(entity, com.Position) {
+		return false
+	}
+	command_log(entity, com, input)
+	return true
+}
+
+// command_log writes the command to the server log according to its log level, and privileged
+// commands to the audit log.
+func command_log(entity Entity, com *Command, input string) {
+	if com.Log == COMMAND_LOG_NEVER {
+		return
+	}
+	ch := entity.GetCharData()
+	logged := com.Log == COMMAND_LOG_ALWAYS
+	if entity.IsPlayer() && entity.(*PlayerProfile).Logged {
+		logged = true
+	}
+	if logged {
+		log.Printf("LOG: [%d]%s: %s", ch.Id, ch.Name, input)
+	}
+	if com.Level >= 100 && entity.IsPlayer() {
+		go audit_record(entity, com, input)
+	}
+}
+
+// command_input_hidden is true if input runs a command that's never logged, password say, so
+// it isn't shown to anyone snooping either.
+func command_input_hidden(input string) bool {
+	args := strings.Fields(input)
+	if len(args) == 0 {
+		return false
+	}
+	commands := command_fuzzy_match(args[0])
+	return len(commands) > 0 && commands[0].Log == COMMAND_LOG_NEVER
+}
+
+// position_rank returns the index of a POSITION_* in positions, -1 if it's not a position.
+func position_rank(position string) int {
+	for i, p := range positions {
+		if strings.EqualFold(p, position) {
+			return i
+		}
+	}
+	return -1
+}
+
+// command_check_position sends the entity the reason they can't act and returns false
+// if they aren't in at least the required position.
+func command_check_position(entity Entity, position string) bool {
+	if position == "" {
+		return true
+	}
+	current := entity_position(entity)
+	if position_rank(current) >= position_rank(position) {
+		return true
+	}
+	switch current {
+	case POSITION_DEAD:
+		entity.Send("\r\n&RYou can't do that when you're dead.&d\r\n")
+	case POSITION_UNCONSCIOUS:
+		entity.Send("\r\n&YYou are unconscious...&d\r\n")
+	case POSITION_SLEEPING:
+		entity.Send("\r\n&cIn your dreams?...&d\r\n")
+	case POSITION_SITTING:
+		if entity.GetCharData().State == ENTITY_STATE_SEDATED {
+			entity.Send("\r\nYou feel too relaxed!\r\n")
+		} else {
+			entity.Send("\r\n&dYou need to stand up first.\r\n")
+		}
+	case POSITION_FIGHTING:
+		entity.Send("\r\n&RNot while you're fighting!&d\r\n")
+	}
+	return false
+}
+
 func command_map_to_func(name string) func(Entity, ...string) {
 	if k, ok := CommandFuncs[name]; ok {
 		return k
@@ -137,7 +373,7 @@ func command_map_to_func(name string) func(Entity, ...string) {
 }
 func command_fuzzy_match(command string) []Command {
 	ret := []Command{}
-	for _, com := range Commands {
+	for _, com := range commands_list() {
 		for _, keyword := range com.Keywords {
 			if len(keyword) < len(command) {
 				continue
@@ -163,21 +399,34 @@ func do_command(entity Entity, input string) {
 	}
 	if strings.HasPrefix(args[0], "'") {
 		args[0] = strings.TrimPrefix(args[0], "'")
-		do_say(entity, args...)
+		if command_check_options(entity, command_find_func("do_say"), strings.Join(args, " ")) {
+			do_say(entity, args...)
+		}
 		entity.Prompt()
 	} else if strings.HasPrefix(args[0], "\"") {
 		args[0] = strings.TrimPrefix(args[0], "\"")
-		do_say_comlink(entity, args...)
+		if command_check_options(entity, command_find_func("do_say_comlink"), strings.Join(args, " ")) {
+			do_say_comlink(entity, args...)
+		}
 		entity.Prompt()
 	} else if strings.HasPrefix(args[0], ".") {
 		args[0] = strings.TrimPrefix(args[0], ".")
-		do_emote(entity, args...)
+		if command_check_options(entity, command_find_func("do_emote"), strings.Join(args, " ")) {
+			do_emote(entity, args...)
+		}
 		entity.Prompt()
 	} else {
 		commands := command_fuzzy_match(args[0])
 		if len(commands) > 0 && commands[0].Level <= entity.GetCharData().Level {
 			a := args[1:]
-			command_map_to_func(commands[0].Func)(entity, a...)
+			if command_check_options(entity, &commands[0], strings.Join(args, " ")) {
+				command_map_to_func(commands[0].Func)(entity, a...)
+			}
+			entity.Prompt()
+		} else if social := social_find(args[0]); social != nil {
+			if command_check_position(entity, POSITION_SITTING) {
+				do_social(entity, social, args[1:]...)
+			}
 			entity.Prompt()
 		} else {
 			if entity.IsPlayer() {
@@ -205,7 +454,7 @@ func do_commands(entity Entity, args ...string) {
 	entity.Send("\r\n%s\r\n", MakeTitle("Commands", ANSI_TITLE_STYLE_NORMAL, ANSI_TITLE_ALIGNMENT_CENTER))
 	entity.Send("&wFor more information, type &yhelp &Y<command>&d\r\n")
 	c := make([]string, 0)
-	for _, com := range Commands {
+	for _, com := range commands_list() {
 		if com.Level > entity.GetCharData().Level {
 			continue
 		}
@@ -26,10 +26,19 @@ import (
 )
 
 type Configuration struct {
-	Name string `yaml:"name"`
-	Data string `yaml:"data"`
-	Addr string `yaml:"addr"`
-	Salt string `yaml:"salt"`
+	Name     string      `yaml:"name"`
+	Data     string      `yaml:"data"`
+	Addr     string      `yaml:"addr"`
+	Salt     string      `yaml:"salt"`
+	Database string      `yaml:"database,omitempty"` // storage backend, yaml (default) or sqlite.
+	Decay    DecayConfig `yaml:"decay,omitempty"`
+}
+
+// DecayConfig is how long (in seconds) things left lying around last before they rot away.
+// 0 turns the timer off, corpses without a timer are cleared by the next area reset instead.
+type DecayConfig struct {
+	Junk   uint `yaml:"junk"`   // items dropped on the floor.
+	Corpse uint `yaml:"corpse"` // corpses, and everything left in them.
 }
 
 var _config *Configuration
@@ -21,7 +21,7 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -38,11 +38,13 @@ type HelpData struct {
 	Keywords []string `yaml:"keywords,flow"`
 	Desc     string   `yaml:"desc"`
 	Level    uint     `yaml:"level"`
+	Filename string   `yaml:"-"` // file name in ./docs
 }
 
 type GameDatabase struct {
 	m               *sync.Mutex
 	db              *gorm.DB
+	store           Database // where the world is loaded from and saved to.
 	clients         []Client
 	entities        []Entity
 	areas           map[string]*AreaData // pointers to the [AreaData] of the game.
@@ -55,12 +57,138 @@ type GameDatabase struct {
 	helps           []*HelpData
 }
 
-// TODO: Flesh out the interfaces and wrap the GameDatabase behind this...
+// Storage backends, set with database: in config.yml.
+const (
+	DATABASE_YAML   = "yaml"   // yaml files in ./data and ./docs (the default).
+	DATABASE_SQLITE = "sqlite" // records in data/game.db.
+)
+
+// Database is the storage behind the [GameDatabase]. It only reads and writes, it never touches the
+// game's in memory state, so it's safe to call while the GameDatabase is locked.
 type Database interface {
+	Name() string
+	LoadAreas() ([]*AreaData, error)
+	LoadArea(name string) (*AreaData, error)
 	SaveArea(area *AreaData) error
-	SaveEntity(entity Entity) error
-	SaveObject(object Item) error
-	SaveShip(ship Ship) error
+	DeleteArea(area *AreaData) error
+	LoadMobs() ([]*CharData, error)
+	SaveMob(mob *CharData) error
+	DeleteMob(mob *CharData) error
+	LoadItems() ([]*ItemData, error)
+	SaveItem(item *ItemData) error
+	LoadShips() ([]*ShipData, error)
+	SaveShip(ship *ShipData) error
+	DeleteShip(ship *ShipData) error
+	LoadShipPrototypes() ([]*ShipData, error)
+	SaveShipPrototype(ship *ShipData) error
+	DeleteShipPrototype(ship *ShipData) error
+	PlayerExists(name string) bool
+	LoadPlayer(name string) (*PlayerProfile, error)
+	LoadPlayers() ([]*PlayerProfile, error)
+	SavePlayer(player *PlayerProfile) error
+	LoadHelps() ([]*HelpData, error)
+	SaveHelp(help *HelpData) error
+}
+
+// database_open returns the storage backend by name, an empty name is the yaml backend.
+func database_open(name string, db *gorm.DB) (Database, error) {
+	switch strings.ToLower(name) {
+	case "", DATABASE_YAML:
+		return &YAMLDatabase{}, nil
+	case DATABASE_SQLITE:
+		return sqlite_database(db)
+	}
+	return nil, Err("unknown database backend %s", name)
+}
+
+// database_open_config opens the storage backend set in config.yml on its own, for tools that
+// only read the world. Unlike [DB] it leaves data/game.db alone unless the world is kept in it.
+func database_open_config() (Database, error) {
+	if !strings.EqualFold(Config().Database, DATABASE_SQLITE) {
+		return database_open(Config().Database, nil)
+	}
+	db, err := gorm.Open(sqlite.Open("data/game.db"), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+	return database_open(DATABASE_SQLITE, db)
+}
+
+// database_migrate copies everything in one storage backend to another, returning how many
+// of each kind of thing were copied. Things already in the destination are overwritten.
+func database_migrate(from Database, to Database) (map[string]int, error) {
+	counts := make(map[string]int)
+	areas, err := from.LoadAreas()
+	if err != nil {
+		return counts, err
+	}
+	for _, area := range areas {
+		if err := to.SaveArea(area); err != nil {
+			return counts, err
+		}
+		counts["areas"]++
+	}
+	mobs, err := from.LoadMobs()
+	if err != nil {
+		return counts, err
+	}
+	for _, mob := range mobs {
+		if err := to.SaveMob(mob); err != nil {
+			return counts, err
+		}
+		counts["mobs"]++
+	}
+	items, err := from.LoadItems()
+	if err != nil {
+		return counts, err
+	}
+	for _, item := range items {
+		if err := to.SaveItem(item); err != nil {
+			return counts, err
+		}
+		counts["items"]++
+	}
+	prototypes, err := from.LoadShipPrototypes()
+	if err != nil {
+		return counts, err
+	}
+	for _, ship := range prototypes {
+		if err := to.SaveShipPrototype(ship); err != nil {
+			return counts, err
+		}
+		counts["ship prototypes"]++
+	}
+	ships, err := from.LoadShips()
+	if err != nil {
+		return counts, err
+	}
+	for _, ship := range ships {
+		if err := to.SaveShip(ship); err != nil {
+			return counts, err
+		}
+		counts["ships"]++
+	}
+	players, err := from.LoadPlayers()
+	if err != nil {
+		return counts, err
+	}
+	for _, player := range players {
+		if err := to.SavePlayer(player); err != nil {
+			return counts, err
+		}
+		counts["players"]++
+	}
+	helps, err := from.LoadHelps()
+	if err != nil {
+		return counts, err
+	}
+	for _, help := range helps {
+		if err := to.SaveHelp(help); err != nil {
+			return counts, err
+		}
+		counts["helps"]++
+	}
+	return counts, nil
 }
 
 func DB() *GameDatabase {
@@ -69,9 +197,15 @@ func DB() *GameDatabase {
 		db, e := gorm.Open(sqlite.Open("data/game.db"), &gorm.Config{})
 		ErrorCheck(e)
 		db.AutoMigrate(&Account{})
+		db.AutoMigrate(&AuditLog{})
+		store, e := database_open(Config().Database, db)
+		if e != nil {
+			log.Fatalf("Unable to open the %s database: %v", Config().Database, e)
+		}
 		_db = new(GameDatabase)
 		_db.m = &sync.Mutex{}
 		_db.db = db
+		_db.store = store
 		_db.clients = make([]Client, 0, 64)
 		_db.entities = make([]Entity, 0)
 		_db.areas = make(map[string]*AreaData)
@@ -82,7 +216,7 @@ func DB() *GameDatabase {
 		_db.ship_prototypes = make(map[uint]*ShipData)
 		_db.starsystems = make([]Starsystem, 0)
 		_db.helps = make([]*HelpData, 0)
-		log.Printf("Database Started.")
+		log.Printf("Database Started using %s.", store.Name())
 	}
 	return _db
 }
@@ -117,6 +251,12 @@ func (d *GameDatabase) RemoveClient(client Client) {
 			}
 		}
 	}
+	// stop anyone being snooped by the leaving client.
+	for _, c := range d.clients {
+		if c != nil && c.GetSnooper() == client {
+			c.SetSnooper(nil)
+		}
+	}
 	index := -1
 	for i, c := range d.clients {
 		if c == nil {
@@ -152,7 +292,7 @@ func (d *GameDatabase) RemoveEntity(entity Entity, isLocked bool) {
 		}
 	}
 	if index > -1 {
-		ret := make([]Entity, len(d.entities)-1)
+		ret := make([]Entity, 0, len(d.entities)-1)
 		ret = append(ret, d.entities[:index]...)
 		ret = append(ret, d.entities[index+1:]...)
 		d.entities = ret
@@ -173,7 +313,7 @@ func (d *GameDatabase) RemoveShip(ship Ship) {
 		}
 	}
 	if index > -1 {
-		ret := make([]Ship, len(d.ships)-1)
+		ret := make([]Ship, 0, len(d.ships)-1)
 		ret = append(ret, d.ships[:index]...)
 		ret = append(ret, d.ships[index+1:]...)
 		d.ships = ret
@@ -197,9 +337,17 @@ func (d *GameDatabase) RemoveArea(area *AreaData) {
 		for _, r := range a.Rooms {
 			delete(d.rooms, r.Id)
 		}
+		delete(d.areas, area.Name)
 	}
 }
 
+// DeleteRoom takes the room with vnum id out of the game.
+func (d *GameDatabase) DeleteRoom(id uint) {
+	d.Lock()
+	defer d.Unlock()
+	delete(d.rooms, id)
+}
+
 // The Mother of all load functions
 func (d *GameDatabase) Load() {
 
@@ -222,58 +370,202 @@ func (d *GameDatabase) Load() {
 	// Load Ships
 	d.LoadShips()
 
+	// Load World State (what's lying around in the rooms)
+	d.LoadWorld()
 }
 
 func (d *GameDatabase) LoadHelps() {
 	log.Print("Loading help files.")
-	flist, err := os.ReadDir("docs")
+	helps, err := d.store.LoadHelps()
 	ErrorCheck(err)
 	d.Lock()
 	defer d.Unlock()
-	for _, help_file := range flist {
-		fpath := fmt.Sprintf("docs/%s", help_file.Name())
-		fp, err := os.ReadFile(fpath)
-		ErrorCheck(err)
-		help := new(HelpData)
-		err = yaml.Unmarshal(fp, help)
-		ErrorCheck(err)
-		d.helps = append(d.helps, help)
-	}
-	log.Printf("%d help files loaded.\n", len(flist))
+	d.helps = append(d.helps, helps...)
+	log.Printf("%d help files loaded.\n", len(helps))
 }
 
 func (d *GameDatabase) LoadAreas() {
 	log.Print("Loading area files.")
-	flist, err := os.ReadDir("data/areas")
+	areas, err := d.store.LoadAreas()
 	ErrorCheck(err)
-	count := 0
-	for _, area_file := range flist {
-		if strings.HasSuffix(area_file.Name(), "yml") {
-			d.LoadArea(area_file.Name())
-			count++
+	loaded := 0
+	for _, area := range areas {
+		if err := area_vnum_conflict(area, d.GetAreas()); err != nil {
+			log.Printf("Not loading area %s: %v", area.Name, err)
+			continue
 		}
+		d.AddArea(area)
+		loaded++
 	}
-	log.Printf("%d areas loaded.\n", count)
+	log.Printf("%d areas loaded.\n", loaded)
 }
 
-func (d *GameDatabase) LoadArea(name string) {
-	fpath := fmt.Sprintf("data/areas/%s", name)
-	fp, err := os.ReadFile(fpath)
-	ErrorCheck(err)
-	area := new(AreaData)
-	err = yaml.Unmarshal(fp, area)
-	ErrorCheck(err)
+// LoadArea reads the area from the database without putting it into the game, see [GameDatabase.AddArea]
+// and [GameDatabase.ReloadArea].
+func (d *GameDatabase) LoadArea(name string) (*AreaData, error) {
+	return d.store.LoadArea(name)
+}
+
+// AddArea puts the area and its rooms into the game.
+func (d *GameDatabase) AddArea(area *AreaData) {
 	d.Lock()
 	defer d.Unlock()
 	for i := range area.Rooms {
 		room := area.Rooms[i]
 		room.Area = area
+		room.ExitFlags = room_copy_exit_flags(room.ExitFlags)
 		d.rooms[room.Id] = &room
 		time.Sleep(1 * time.Millisecond)
 	}
 	d.areas[area.Name] = area
 }
 
+// GetArea returns the live area named name (any case), nil if there isn't one.
+func (d *GameDatabase) GetArea(name string) *AreaData {
+	d.Lock()
+	defer d.Unlock()
+	for _, area := range d.areas {
+		if strings.EqualFold(area.Name, name) {
+			return area
+		}
+	}
+	return nil
+}
+
+// GetAreas returns the live areas sorted by name.
+func (d *GameDatabase) GetAreas() []*AreaData {
+	d.Lock()
+	defer d.Unlock()
+	ret := make([]*AreaData, 0, len(d.areas))
+	for _, area := range d.areas {
+		ret = append(ret, area)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	return ret
+}
+
+// IsAreaLoaded is true while area is the copy in the game. Removing or reloading an area replaces it.
+func (d *GameDatabase) IsAreaLoaded(area *AreaData) bool {
+	d.Lock()
+	defer d.Unlock()
+	for _, a := range d.areas {
+		if a == area {
+			return true
+		}
+	}
+	return false
+}
+
+// AreaReload is what changed when an area was swapped for a fresh copy by [GameDatabase.ReloadArea].
+type AreaReload struct {
+	Added     []uint // vnums of rooms that are new.
+	Changed   []uint // vnums of rooms that were edited.
+	Removed   []uint // vnums of rooms that are gone.
+	Entities  int    // entities moved out of removed rooms.
+	Items     int    // items moved out of removed rooms.
+	Kept      int    // spawned mobs carried over to the new spawns.
+	Despawned int    // spawned mobs whose spawn is gone.
+}
+
+// ReloadArea puts area into the game in place of the live area with the same name. Whatever is
+// lying in the old rooms is moved into the new ones, anyone standing in a room that no longer
+// exists is moved to the first room of the area, and spawned mobs are handed to the matching
+// spawn in the new area. Doesn't reset the area, see [area_reset].
+func (d *GameDatabase) ReloadArea(area *AreaData) *AreaReload {
+	d.Lock()
+	defer d.Unlock()
+	ret := &AreaReload{
+		Added:   make([]uint, 0),
+		Changed: make([]uint, 0),
+		Removed: make([]uint, 0),
+	}
+	rooms := make(map[uint]*RoomData)
+	for i := range area.Rooms {
+		room := area.Rooms[i]
+		room.Area = area
+		room.Items = make([]Item, 0)
+		room.ExitFlags = room_copy_exit_flags(room.ExitFlags)
+		rooms[room.Id] = &room
+	}
+	// where things in removed rooms go.
+	fallback := uint(100)
+	if len(area.Rooms) > 0 {
+		fallback = area.Rooms[0].Id
+	}
+	old := d.areas[area.Name]
+	existed := make(map[uint]bool)
+	removed := make(map[uint]bool)
+	if old != nil {
+		for i := range old.Rooms {
+			proto := &old.Rooms[i]
+			existed[proto.Id] = true
+			live := d.rooms[proto.Id]
+			room, ok := rooms[proto.Id]
+			if !ok {
+				ret.Removed = append(ret.Removed, proto.Id)
+				removed[proto.Id] = true
+				room = rooms[fallback]
+				if room == nil {
+					room = d.rooms[fallback]
+				}
+				if live != nil {
+					ret.Items += len(live.Items)
+				}
+			} else if !room_prototype_equal(proto, room) {
+				ret.Changed = append(ret.Changed, proto.Id)
+			}
+			if live != nil && room != nil {
+				room.Items = append(room.Items, live.Items...)
+			}
+			delete(d.rooms, proto.Id)
+		}
+		// hand the mobs over, same mob in the same room first, then the same mob anywhere.
+		claimed := make([]bool, len(old.Mobs))
+		for _, same_room := range []bool{true, false} {
+			for i := range area.Mobs {
+				spawn := &area.Mobs[i]
+				if spawn.entity != nil {
+					continue
+				}
+				for j, o := range old.Mobs {
+					if claimed[j] || o.entity == nil || o.Mob != spawn.Mob || (same_room && o.Room != spawn.Room) {
+						continue
+					}
+					claimed[j] = true
+					spawn.entity = o.entity
+					ret.Kept++
+					break
+				}
+			}
+		}
+		for j, o := range old.Mobs {
+			if !claimed[j] && o.entity != nil {
+				d.RemoveEntity(o.entity, true)
+				ret.Despawned++
+			}
+		}
+	}
+	for id, room := range rooms {
+		if !existed[id] {
+			ret.Added = append(ret.Added, id)
+		}
+		d.rooms[id] = room
+	}
+	for _, e := range d.entities {
+		if e == nil || e.ShipId() > 0 || !removed[e.RoomId()] {
+			continue
+		}
+		e.GetCharData().Room = fallback
+		e.Send("\r\n&YThe world shifts around you and you find yourself somewhere else.&d\r\n")
+		ret.Entities++
+	}
+	d.areas[area.Name] = area
+	sort.Slice(ret.Added, func(i, j int) bool { return ret.Added[i] < ret.Added[j] })
+	sort.Slice(ret.Changed, func(i, j int) bool { return ret.Changed[i] < ret.Changed[j] })
+	sort.Slice(ret.Removed, func(i, j int) bool { return ret.Removed[i] < ret.Removed[j] })
+	return ret
+}
+
 func (d *GameDatabase) LoadPlanets() {
 	log.Printf("Loading planet files.")
 	flist, err := os.ReadDir("data/planets")
@@ -294,104 +586,74 @@ func (d *GameDatabase) LoadPlanets() {
 
 func (d *GameDatabase) LoadItems() {
 	log.Print("Loading item files.")
-	err := filepath.Walk("data/items",
-		func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err
-			}
-			if !info.IsDir() {
-				if strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml") {
-					d.LoadItem(path)
-				}
-			}
-			return nil
-		})
-	ErrorCheck(err)
-	log.Printf("%d items loaded.", len(d.items))
-}
-
-func (d *GameDatabase) LoadItem(path string) {
-	fp, err := os.ReadFile(path)
-	ErrorCheck(err)
-	item := new(ItemData)
-	err = yaml.Unmarshal(fp, item)
+	items, err := d.store.LoadItems()
 	ErrorCheck(err)
-	item.Filename = path
 	d.Lock()
 	defer d.Unlock()
-	d.items[item.Id] = item
+	for _, item := range items {
+		d.items[item.Id] = item
+	}
+	log.Printf("%d items loaded.", len(d.items))
 }
 
 func (d *GameDatabase) LoadMobs() {
 	log.Print("Loading mob files.")
-	err := filepath.Walk("data/mobs",
-		func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err
-			}
-			if !info.IsDir() {
-				if strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml") {
-					d.LoadMob(path)
-				}
-			}
-			return nil
-		})
-	ErrorCheck(err)
-	log.Printf("%d mobs loaded.", len(d.mobs))
-}
-func (d *GameDatabase) LoadMob(path string) {
-	fp, err := os.ReadFile(path)
+	mobs, err := d.store.LoadMobs()
 	ErrorCheck(err)
-	ch := new(CharData)
-	err = yaml.Unmarshal(fp, ch)
-	ErrorCheck(err)
-	ch.Filename = path
 	d.Lock()
 	defer d.Unlock()
-	d.mobs[ch.Id] = ch
+	for _, mob := range mobs {
+		d.mobs[mob.Id] = mob
+	}
+	log.Printf("%d mobs loaded.", len(d.mobs))
 }
+
 func (d *GameDatabase) LoadShips() {
 	log.Print("Loading ship files.")
-	err := filepath.Walk("data/ships",
-		func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err
-			}
-			if !info.IsDir() {
-				if strings.Contains(path, "prototype") {
-					if strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml") {
-						d.LoadShipPrototype(path)
-					}
-				} else {
-					if strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml") {
-						d.LoadShip(path)
-					}
-				}
-			}
-			return nil
-		})
+	prototypes, err := d.store.LoadShipPrototypes()
 	ErrorCheck(err)
+	ships, err := d.store.LoadShips()
+	ErrorCheck(err)
+	d.Lock()
+	defer d.Unlock()
+	for _, ship := range prototypes {
+		d.ship_prototypes[ship.Id] = ship
+	}
+	for _, ship := range ships {
+		ship_load_rooms(d, ship)
+		ship_load_hyper(ship)
+		d.ships = append(d.ships, ship)
+	}
 	log.Printf("%d ships loaded. %d prototypes.", len(d.ships), len(d.ship_prototypes))
 }
-func (d *GameDatabase) LoadShip(path string) {
-	fp, err := os.ReadFile(path)
-	ErrorCheck(err)
-	ship := new(ShipData)
-	err = yaml.Unmarshal(fp, ship)
-	ErrorCheck(err)
+
+// yaml_copy deep copies src into dst the same way saving and loading it again would.
+func yaml_copy(src interface{}, dst interface{}) error {
+	buf, err := yaml.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(buf, dst)
+}
+
+// AddItem makes a copy of item the prototype spawned for its vnum.
+func (d *GameDatabase) AddItem(item *ItemData) {
+	i := new(ItemData)
+	ErrorCheck(yaml_copy(item, i))
+	i.Filename = item.Filename
 	d.Lock()
 	defer d.Unlock()
-	d.ships = append(d.ships, ship)
+	d.items[i.Id] = i
 }
-func (d *GameDatabase) LoadShipPrototype(path string) {
-	fp, err := os.ReadFile(path)
-	ErrorCheck(err)
-	ship := new(ShipData)
-	err = yaml.Unmarshal(fp, ship)
-	ErrorCheck(err)
+
+// AddMob makes a copy of mob the prototype spawned for its vnum.
+func (d *GameDatabase) AddMob(mob *CharData) {
+	ch := new(CharData)
+	ErrorCheck(yaml_copy(mob, ch))
+	ch.Filename = mob.Filename
 	d.Lock()
 	defer d.Unlock()
-	d.ship_prototypes[ship.Id] = ship
+	d.mobs[ch.Id] = ch
 }
 
 // The Mother of all save functions
@@ -400,43 +662,68 @@ func (d *GameDatabase) Save() {
 	defer d.Unlock()
 	echo_all("\r\n&xSaving Game World&d\r\n")
 	t := time.Now()
-	d.SaveAreas()
-	d.SaveMobs()
-	d.SaveItems()
-	d.SaveShips()
-	d.SavePlayers()
+	failed := false
+	for _, save := range []func() error{d.SaveAreas, d.SaveMobs, d.SaveItems, d.SaveShips, d.SaveWorld, d.SavePlayers} {
+		if err := save(); err != nil {
+			failed = true
+		}
+	}
+	if failed {
+		echo_all("\r\n&RSave failed, check the server log!&d\r\n")
+	}
 	echo_all(sprintf("\r\n&xSave took %s&d\r\n", time.Since(t).String()))
 }
 
-func (d *GameDatabase) SaveAreas() {
+func (d *GameDatabase) SaveAreas() error {
+	var ret error
 	for _, area := range d.areas {
-		d.SaveArea(area)
+		if err := d.SaveArea(area); err != nil {
+			ErrorCheck(err)
+			ret = err
+		}
 	}
+	return ret
 }
-func (d *GameDatabase) SaveItems() {
+func (d *GameDatabase) SaveItems() error {
+	var ret error
 	for _, item := range d.items {
-		d.SaveItem(item)
+		if err := d.SaveItem(item); err != nil {
+			ErrorCheck(err)
+			ret = err
+		}
 	}
+	return ret
 }
-func (d *GameDatabase) SaveMobs() {
+func (d *GameDatabase) SaveMobs() error {
+	var ret error
 	for _, mob := range d.mobs {
-		d.SaveMob(mob)
+		if err := d.SaveMob(mob); err != nil {
+			ErrorCheck(err)
+			ret = err
+		}
 	}
+	return ret
 }
 
-func (d *GameDatabase) SaveShips() {
+func (d *GameDatabase) SaveShips() error {
+	var ret error
 	for _, ship := range d.ship_prototypes {
-		buf, err := yaml.Marshal(ship)
-		ErrorCheck(err)
-		err = os.WriteFile(sprintf("data/ships/prototypes/%s.yml", strings.ToLower(strings.ReplaceAll(ship.Type, " ", "_"))), buf, 0755)
-		ErrorCheck(err)
+		if err := d.store.SaveShipPrototype(ship); err != nil {
+			ErrorCheck(err)
+			ret = err
+		}
 	}
 	for _, ship := range d.ships {
-		d.SaveShip(ship)
+		if err := d.SaveShip(ship); err != nil {
+			ErrorCheck(err)
+			ret = err
+		}
 	}
+	return ret
 }
 
-func (d *GameDatabase) SavePlayers() {
+func (d *GameDatabase) SavePlayers() error {
+	var ret error
 	// lets compact memory while we are at it...
 	el := make([]Entity, 0)
 	for _, e := range d.entities {
@@ -445,80 +732,87 @@ func (d *GameDatabase) SavePlayers() {
 		}
 		if e.IsPlayer() {
 			player := e.(*PlayerProfile)
-			d.SavePlayerData(player)
+			if err := d.SavePlayerData(player); err != nil {
+				ErrorCheck(err)
+				ret = err
+			}
 		}
 		el = append(el, e)
 	}
 	// remove old slice with new slice... like a garbage collector.
 	d.entities = el
+	return ret
 }
 
-func (d *GameDatabase) SaveShip(ship Ship) {
-	buf, err := yaml.Marshal(ship)
-	ErrorCheck(err)
-	err = os.WriteFile(sprintf("data/ships/%s.yml", strings.ToLower(strings.ReplaceAll(ship.GetData().Name, " ", "_"))), buf, 0755)
-	ErrorCheck(err)
+// SaveShip saves the ship along with whatever's been left lying around inside it.
+func (d *GameDatabase) SaveShip(ship Ship) error {
+	// save a copy carrying the room items and any jump in progress, so the live ship is left alone.
+	s := *ship.GetData()
+	s.Cargo = ship_room_items(ship.GetData())
+	s.Hyper = ship_hyper_state(ship.GetData())
+	return d.store.SaveShip(&s)
 }
 
-func (d *GameDatabase) SaveArea(area *AreaData) {
-	buf, err := yaml.Marshal(area)
-	ErrorCheck(err)
-	err = os.WriteFile(sprintf("data/areas/%s.yml", area.Name), buf, 0755)
+func (d *GameDatabase) DeleteShip(ship Ship) error {
+	return d.store.DeleteShip(ship.GetData())
+}
+
+// DeleteShipPrototype removes the prototype ship was spawned from.
+func (d *GameDatabase) DeleteShipPrototype(ship Ship) error {
+	d.Lock()
+	proto, ok := d.ship_prototypes[ship.GetData().OId]
+	d.Unlock()
+	if !ok {
+		return Err("no prototype for ship %s", ship.GetData().Name)
+	}
+	return d.store.DeleteShipPrototype(proto)
+}
+
+func (d *GameDatabase) SaveArea(area *AreaData) error {
+	if err := d.store.SaveArea(area); err != nil {
+		return err
+	}
 	for _, m := range area.Mobs {
-		mob := d.mobs[m.Mob]
-		d.SaveMob(mob)
+		if mob, ok := d.mobs[m.Mob]; ok {
+			if err := d.SaveMob(mob); err != nil {
+				return err
+			}
+		}
 	}
 	for _, i := range area.Items {
-		item := d.items[i.Item]
-		d.SaveItem(item)
+		if item, ok := d.items[i.Item]; ok {
+			if err := d.SaveItem(item); err != nil {
+				return err
+			}
+		}
 	}
-	ErrorCheck(err)
+	return nil
 }
-func (d *GameDatabase) SaveItem(item *ItemData) {
-	buf, err := yaml.Marshal(item)
-	ErrorCheck(err)
-	dir := filepath.Dir(item.Filename)
-	os.MkdirAll(dir, 0755)
-	err = os.WriteFile(item.Filename, buf, 0755)
-	ErrorCheck(err)
+func (d *GameDatabase) SaveItem(item *ItemData) error {
+	return d.store.SaveItem(item)
 }
-func (d *GameDatabase) SaveMob(mob *CharData) {
-	buf, err := yaml.Marshal(mob)
-	ErrorCheck(err)
-	dir := filepath.Dir(mob.Filename)
-	os.MkdirAll(dir, 0755)
-	err = os.WriteFile(mob.Filename, buf, 0755)
-	ErrorCheck(err)
+func (d *GameDatabase) SaveMob(mob *CharData) error {
+	return d.store.SaveMob(mob)
+}
+func (d *GameDatabase) DeleteMob(mob *CharData) error {
+	return d.store.DeleteMob(mob)
 }
 
+// GetPlayer returns the player if they're online, otherwise they're loaded from the database.
 func (d *GameDatabase) GetPlayer(name string) *PlayerProfile {
-	d.Lock()
-	defer d.Unlock()
-	var player *PlayerProfile
-	for i := range d.entities {
-		e := d.entities[i]
-		if e != nil {
-			if e.IsPlayer() {
-				if e.GetCharData().Name == name {
-					player = e.(*PlayerProfile)
-				}
-			}
-		}
-	}
-	d.Unlock() // unlock early
-	// Player isn't online
-	if player == nil {
-		path := fmt.Sprintf("data/accounts/%s/%s.yml", strings.ToLower(name[0:1]), strings.ToLower(name))
-		player = d.ReadPlayerData(path)
+	if e := d.GetPlayerEntityByName(name); e != nil {
+		return e.(*PlayerProfile)
 	}
-	return player
+	return d.ReadPlayerData(name)
 }
 
-func (d *GameDatabase) ReadPlayerData(filename string) *PlayerProfile {
-	fp, err := os.ReadFile(filename)
-	ErrorCheck(err)
-	p_data := new(PlayerProfile)
-	err = yaml.Unmarshal(fp, p_data)
+func (d *GameDatabase) PlayerExists(name string) bool {
+	return d.store.PlayerExists(name)
+}
+
+// ReadPlayerData loads a player by name from the database, nil if they can't be read.
+func (d *GameDatabase) ReadPlayerData(name string) *PlayerProfile {
+	p_data, err := d.store.LoadPlayer(name)
 	if err != nil {
 		ErrorCheck(err)
 		return nil
@@ -532,13 +826,8 @@ func (d *GameDatabase) ReadPlayerData(filename string) *PlayerProfile {
 	return p_data
 }
 
-func (d *GameDatabase) SavePlayerData(player *PlayerProfile) {
-	name := strings.ToLower(player.Char.Name)
-	filename := fmt.Sprintf("data/accounts/%s/%s.yml", name[0:1], name)
-	buf, err := yaml.Marshal(player)
-	ErrorCheck(err)
-	err = os.WriteFile(filename, buf, 0755)
-	ErrorCheck(err)
+func (d *GameDatabase) SavePlayerData(player *PlayerProfile) error {
+	return d.store.SavePlayer(player)
 }
 
 func (d *GameDatabase) GetPlayerEntityByName(name string) Entity {
@@ -557,6 +846,19 @@ func (d *GameDatabase) GetPlayerEntityByName(name string) Entity {
 	}
 	return nil
 }
+
+// GetPlayerEntities returns the players in the game, a copy that's safe to use without the lock.
+func (d *GameDatabase) GetPlayerEntities() []Entity {
+	d.Lock()
+	defer d.Unlock()
+	ret := make([]Entity, 0)
+	for _, e := range d.entities {
+		if e != nil && e.IsPlayer() {
+			ret = append(ret, e)
+		}
+	}
+	return ret
+}
 func (d *GameDatabase) ReadCharData(filename string) *CharData {
 	fp, err := os.ReadFile(filename)
 	ErrorCheck(err)
@@ -565,11 +867,8 @@ func (d *GameDatabase) ReadCharData(filename string) *CharData {
 	return char_data
 }
 
-func (d *GameDatabase) SaveCharData(char_data *CharData, filename string) {
-	buf, err := yaml.Marshal(char_data)
-	ErrorCheck(err)
-	err = os.WriteFile(filename, buf, 0755)
-	ErrorCheck(err)
+func (d *GameDatabase) SaveCharData(char_data *CharData, filename string) error {
+	return write_yaml(filename, char_data)
 }
 
 func (d *GameDatabase) AddEntity(entity Entity) {
@@ -601,6 +900,25 @@ func (d *GameDatabase) SpawnShip(ship Ship) Ship {
 	return s
 }
 
+// AddShipPrototype makes ship a prototype that ships of its type are spawned from.
+func (d *GameDatabase) AddShipPrototype(ship *ShipData) {
+	d.Lock()
+	defer d.Unlock()
+	d.ship_prototypes[ship.OId] = ship
+}
+
+// GetShipPrototype returns the prototype for ships of shipType, nil if there isn't one.
+func (d *GameDatabase) GetShipPrototype(shipType string) *ShipData {
+	d.Lock()
+	defer d.Unlock()
+	for _, ship := range d.ship_prototypes {
+		if strings.EqualFold(ship.Type, shipType) {
+			return ship
+		}
+	}
+	return nil
+}
+
 func (d *GameDatabase) GetShip(shipId uint) Ship {
 	d.Lock()
 	defer d.Unlock()
@@ -635,6 +953,17 @@ func (d *GameDatabase) GetShipsInSystem(system string) []Ship {
 	}
 	return ret
 }
+func (d *GameDatabase) GetShipsInSpace() []Ship {
+	ret := make([]Ship, 0)
+	d.Lock()
+	defer d.Unlock()
+	for _, ship := range d.ships {
+		if ship.GetData().InSpace {
+			ret = append(ret, ship)
+		}
+	}
+	return ret
+}
 func (d *GameDatabase) GetShipsInRoom(roomId uint) []Ship {
 	d.Lock()
 	defer d.Unlock()
@@ -684,15 +1013,8 @@ func (d *GameDatabase) GetRoom(roomId uint, shipId uint) *RoomData {
 				}
 			}
 		}
-	} else {
-		for _, r := range d.rooms {
-			if r == nil {
-				continue
-			}
-			if r.Id == roomId {
-				return r
-			}
-		}
+	} else if r, ok := d.rooms[roomId]; ok {
+		return r
 	}
 	return nil
 }
@@ -716,47 +1038,133 @@ func (d *GameDatabase) GetNextRoomVnum(roomId uint, shipId uint) uint {
 			}
 		}
 	} else {
-		lastVnum := uint(0)
 		// let's get the room's area
 		var room *RoomData
 		if r, ok := d.rooms[roomId]; ok {
 			room = r
 		}
-		if room == nil {
+		if room == nil || room.Area == nil {
 			return 0
 		}
-		for _, r := range room.Area.Rooms {
-			if r.Name == "A void" { // return the first void prototype room
-				return r.Id
+		r := room.Area.GetVnums(VNUM_ROOM)
+		for _, proto := range room.Area.Rooms {
+			if proto.Name == "A void" { // return the first void prototype room
+				return proto.Id
 			}
-			if r.Id > lastVnum {
-				lastVnum = r.Id
+		}
+		if r == nil {
+			return 0
+		}
+		for i := r[0]; i <= r[1]; i++ {
+			if _, ok := d.rooms[i]; !ok {
+				return i
 			}
 		}
-		return lastVnum + 1
 	}
 	return 0
 }
-func (d *GameDatabase) GetNextItemVnum() uint {
+
+// GetNextItemVnum returns the first unused item vnum in the area's item range, 0 if there isn't one.
+func (d *GameDatabase) GetNextItemVnum(area *AreaData) uint {
 	d.Lock()
 	defer d.Unlock()
-	for i := uint(1); i < (^uint(0)); i++ {
+	r := area.GetVnums(VNUM_ITEM)
+	if r == nil {
+		return 0
+	}
+	for i := r[0]; i <= r[1]; i++ {
 		if _, ok := d.items[i]; !ok {
 			return i
 		}
 	}
 	return 0
 }
-func (d *GameDatabase) GetNextMobVnum() uint {
+
+// GetNextMobVnum returns the first unused mob vnum in the area's mob range, 0 if there isn't one.
+func (d *GameDatabase) GetNextMobVnum(area *AreaData) uint {
 	d.Lock()
 	defer d.Unlock()
-	for i := uint(1); i < (^uint(0)); i++ {
+	r := area.GetVnums(VNUM_MOB)
+	if r == nil {
+		return 0
+	}
+	for i := r[0]; i <= r[1]; i++ {
 		if _, ok := d.mobs[i]; !ok {
 			return i
 		}
 	}
 	return 0
 }
+
+// CountMobs counts the living copies of mob in the game, or in the room if roomId is set.
+func (d *GameDatabase) CountMobs(mobId uint, roomId uint, shipId uint) uint {
+	d.Lock()
+	defer d.Unlock()
+	count := uint(0)
+	for _, e := range d.entities {
+		if e == nil || e.IsPlayer() {
+			continue
+		}
+		ch := e.GetCharData()
+		if ch.OId != mobId || ch.State == ENTITY_STATE_DEAD {
+			continue
+		}
+		if roomId > 0 && (ch.Room != roomId || ch.Ship != shipId) {
+			continue
+		}
+		count++
+	}
+	return count
+}
+
+// CountItems counts the copies of item in the game, lying in rooms, carried or inside other items.
+func (d *GameDatabase) CountItems(itemId uint) uint {
+	d.Lock()
+	defer d.Unlock()
+	var count func(items []Item) uint
+	count = func(items []Item) uint {
+		n := uint(0)
+		for _, i := range items {
+			if i == nil {
+				continue
+			}
+			if item_prototype_id(i) == itemId {
+				n++
+			}
+			n += count(i.GetData().Items)
+		}
+		return n
+	}
+	n := uint(0)
+	for _, room := range d.rooms {
+		n += count(room.Items)
+	}
+	for _, ship := range d.ships {
+		for _, room := range ship.GetData().Rooms {
+			if room != nil {
+				n += count(room.Items)
+			}
+		}
+	}
+	for _, e := range d.entities {
+		if e == nil {
+			continue
+		}
+		ch := e.GetCharData()
+		for _, i := range ch.Equipment {
+			if i != nil {
+				n += count([]Item{i})
+			}
+		}
+		for _, i := range ch.Inventory {
+			if i != nil {
+				n += count([]Item{i})
+			}
+		}
+	}
+	return n
+}
+
 func (d *GameDatabase) GetNextShipVnum() uint {
 	d.Lock()
 	defer d.Unlock()
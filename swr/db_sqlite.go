@@ -0,0 +1,298 @@
+/*  Star Wars Role-Playing Mud
+ *  Copyright (C) 2022 @{See Authors}
+ *
+ *  This program is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  This program is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *  GNU General Public License for more details.
+ *
+ *  You should have received a copy of the GNU General Public License
+ *  along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+package swr
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+// Kinds of [StoreRecord] kept by the [SQLiteDatabase].
+const (
+	STORE_KIND_AREA           = "area"
+	STORE_KIND_MOB            = "mob"
+	STORE_KIND_ITEM           = "item"
+	STORE_KIND_SHIP           = "ship"
+	STORE_KIND_SHIP_PROTOTYPE = "ship_prototype"
+	STORE_KIND_PLAYER         = "player"
+	STORE_KIND_HELP           = "help"
+)
+
+// StoreRecord is one area, mob, item, ship, player or help file stored in sqlite. Data is the same
+// yaml document the [YAMLDatabase] would have written, Filename is where it lives (or lived) on disk
+// so migrating back to yaml keeps the same layout.
+type StoreRecord struct {
+	ID       uint   `gorm:"primarykey"`
+	Kind     string `gorm:"index:idx_kind_key,unique"`
+	Key      string `gorm:"index:idx_kind_key,unique"`
+	Filename string
+	Data     []byte
+}
+
+// SQLiteDatabase is the [Database] kept in data/game.db, using the same gorm connection as the accounts.
+type SQLiteDatabase struct {
+	db *gorm.DB
+}
+
+func sqlite_database(db *gorm.DB) (*SQLiteDatabase, error) {
+	if err := db.AutoMigrate(&StoreRecord{}); err != nil {
+		return nil, err
+	}
+	return &SQLiteDatabase{db: db}, nil
+}
+
+func (s *SQLiteDatabase) Name() string {
+	return DATABASE_SQLITE
+}
+
+// put creates or replaces the record for kind/key.
+func (s *SQLiteDatabase) put(kind string, key string, filename string, v interface{}) error {
+	buf, err := yaml.Marshal(v)
+	if err != nil {
+		return Err("unable to marshal %s %s: %v", kind, key, err)
+	}
+	rec := &StoreRecord{}
+	err = s.db.Where("kind = ? AND key = ?", kind, key).Limit(1).Find(rec).Error
+	if err != nil {
+		return err
+	}
+	rec.Kind = kind
+	rec.Key = key
+	rec.Filename = filename
+	rec.Data = buf
+	return s.db.Save(rec).Error
+}
+
+// get reads the record for kind/key into v, returning its filename.
+func (s *SQLiteDatabase) get(kind string, key string, v interface{}) (string, error) {
+	rec := &StoreRecord{}
+	err := s.db.Where("kind = ? AND key = ?", kind, key).First(rec).Error
+	if err != nil {
+		return "", Err("unable to read %s %s: %v", kind, key, err)
+	}
+	if err := yaml.Unmarshal(rec.Data, v); err != nil {
+		return "", Err("unable to read %s %s: %v", kind, key, err)
+	}
+	return rec.Filename, nil
+}
+
+// all returns every record of a kind.
+func (s *SQLiteDatabase) all(kind string) ([]StoreRecord, error) {
+	ret := make([]StoreRecord, 0)
+	err := s.db.Where("kind = ?", kind).Order("key").Find(&ret).Error
+	return ret, err
+}
+
+func (s *SQLiteDatabase) remove(kind string, key string) error {
+	return s.db.Where("kind = ? AND key = ?", kind, key).Delete(&StoreRecord{}).Error
+}
+
+func sqlite_ship_key(ship *ShipData) string {
+	return strings.ToLower(strings.ReplaceAll(ship.Name, " ", "_"))
+}
+
+func sqlite_ship_prototype_key(ship *ShipData) string {
+	return strings.ToLower(strings.ReplaceAll(ship.Type, " ", "_"))
+}
+
+func (s *SQLiteDatabase) LoadAreas() ([]*AreaData, error) {
+	recs, err := s.all(STORE_KIND_AREA)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*AreaData, 0, len(recs))
+	for _, rec := range recs {
+		area := new(AreaData)
+		if err := yaml.Unmarshal(rec.Data, area); err != nil {
+			ErrorCheck(Err("unable to read area %s: %v", rec.Key, err))
+			continue
+		}
+		ret = append(ret, area)
+	}
+	return ret, nil
+}
+
+func (s *SQLiteDatabase) LoadArea(name string) (*AreaData, error) {
+	area := new(AreaData)
+	if _, err := s.get(STORE_KIND_AREA, name, area); err != nil {
+		return nil, err
+	}
+	return area, nil
+}
+
+func (s *SQLiteDatabase) SaveArea(area *AreaData) error {
+	return s.put(STORE_KIND_AREA, area.Name, sprintf("data/areas/%s.yml", area.Name), area)
+}
+
+func (s *SQLiteDatabase) DeleteArea(area *AreaData) error {
+	return s.remove(STORE_KIND_AREA, area.Name)
+}
+
+func (s *SQLiteDatabase) LoadMobs() ([]*CharData, error) {
+	recs, err := s.all(STORE_KIND_MOB)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*CharData, 0, len(recs))
+	for _, rec := range recs {
+		ch := new(CharData)
+		if err := yaml.Unmarshal(rec.Data, ch); err != nil {
+			ErrorCheck(Err("unable to read mob %s: %v", rec.Key, err))
+			continue
+		}
+		ch.Filename = rec.Filename
+		ret = append(ret, ch)
+	}
+	return ret, nil
+}
+
+func (s *SQLiteDatabase) SaveMob(mob *CharData) error {
+	return s.put(STORE_KIND_MOB, sprintf("%d", mob.Id), mob.Filename, mob)
+}
+
+func (s *SQLiteDatabase) DeleteMob(mob *CharData) error {
+	return s.remove(STORE_KIND_MOB, sprintf("%d", mob.Id))
+}
+
+func (s *SQLiteDatabase) LoadItems() ([]*ItemData, error) {
+	recs, err := s.all(STORE_KIND_ITEM)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*ItemData, 0, len(recs))
+	for _, rec := range recs {
+		item := new(ItemData)
+		if err := yaml.Unmarshal(rec.Data, item); err != nil {
+			ErrorCheck(Err("unable to read item %s: %v", rec.Key, err))
+			continue
+		}
+		item.Filename = rec.Filename
+		ret = append(ret, item)
+	}
+	return ret, nil
+}
+
+func (s *SQLiteDatabase) SaveItem(item *ItemData) error {
+	return s.put(STORE_KIND_ITEM, sprintf("%d", item.Id), item.Filename, item)
+}
+
+func (s *SQLiteDatabase) load_ships(kind string) ([]*ShipData, error) {
+	recs, err := s.all(kind)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*ShipData, 0, len(recs))
+	for _, rec := range recs {
+		ship := new(ShipData)
+		if err := yaml.Unmarshal(rec.Data, ship); err != nil {
+			ErrorCheck(Err("unable to read %s %s: %v", kind, rec.Key, err))
+			continue
+		}
+		ship.Filename = rec.Filename
+		ret = append(ret, ship)
+	}
+	return ret, nil
+}
+
+func (s *SQLiteDatabase) LoadShips() ([]*ShipData, error) {
+	return s.load_ships(STORE_KIND_SHIP)
+}
+
+func (s *SQLiteDatabase) SaveShip(ship *ShipData) error {
+	return s.put(STORE_KIND_SHIP, sqlite_ship_key(ship), ship.Filename, ship)
+}
+
+func (s *SQLiteDatabase) DeleteShip(ship *ShipData) error {
+	return s.remove(STORE_KIND_SHIP, sqlite_ship_key(ship))
+}
+
+func (s *SQLiteDatabase) LoadShipPrototypes() ([]*ShipData, error) {
+	return s.load_ships(STORE_KIND_SHIP_PROTOTYPE)
+}
+
+func (s *SQLiteDatabase) SaveShipPrototype(ship *ShipData) error {
+	return s.put(STORE_KIND_SHIP_PROTOTYPE, sqlite_ship_prototype_key(ship), ship.Filename, ship)
+}
+
+func (s *SQLiteDatabase) DeleteShipPrototype(ship *ShipData) error {
+	return s.remove(STORE_KIND_SHIP_PROTOTYPE, sqlite_ship_prototype_key(ship))
+}
+
+func (s *SQLiteDatabase) PlayerExists(name string) bool {
+	var count int64
+	err := s.db.Model(&StoreRecord{}).Where("kind = ? AND key = ?", STORE_KIND_PLAYER, strings.ToLower(name)).Count(&count).Error
+	ErrorCheck(err)
+	return count > 0
+}
+
+func (s *SQLiteDatabase) LoadPlayer(name string) (*PlayerProfile, error) {
+	player := new(PlayerProfile)
+	if _, err := s.get(STORE_KIND_PLAYER, strings.ToLower(name), player); err != nil {
+		return nil, err
+	}
+	return player, nil
+}
+
+func (s *SQLiteDatabase) LoadPlayers() ([]*PlayerProfile, error) {
+	recs, err := s.all(STORE_KIND_PLAYER)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*PlayerProfile, 0, len(recs))
+	for _, rec := range recs {
+		player := new(PlayerProfile)
+		if err := yaml.Unmarshal(rec.Data, player); err != nil {
+			ErrorCheck(Err("unable to read player %s: %v", rec.Key, err))
+			continue
+		}
+		ret = append(ret, player)
+	}
+	return ret, nil
+}
+
+func (s *SQLiteDatabase) SavePlayer(player *PlayerProfile) error {
+	name := strings.ToLower(player.Char.Name)
+	return s.put(STORE_KIND_PLAYER, name, yaml_player_filename(name), player)
+}
+
+func (s *SQLiteDatabase) LoadHelps() ([]*HelpData, error) {
+	recs, err := s.all(STORE_KIND_HELP)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*HelpData, 0, len(recs))
+	for _, rec := range recs {
+		help := new(HelpData)
+		if err := yaml.Unmarshal(rec.Data, help); err != nil {
+			ErrorCheck(Err("unable to read help %s: %v", rec.Key, err))
+			continue
+		}
+		help.Filename = rec.Filename
+		ret = append(ret, help)
+	}
+	return ret, nil
+}
+
+func (s *SQLiteDatabase) SaveHelp(help *HelpData) error {
+	if help.Filename == "" {
+		help.Filename = sprintf("%s.yml", strings.ToLower(strings.ReplaceAll(help.Name, " ", "_")))
+	}
+	return s.put(STORE_KIND_HELP, help.Filename, help.Filename, help)
+}
@@ -0,0 +1,296 @@
+/*  Star Wars Role-Playing Mud
+ *  Copyright (C) 2022 @{See Authors}
+ *
+ *  This program is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  This program is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *  GNU General Public License for more details.
+ *
+ *  You should have received a copy of the GNU General Public License
+ *  along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+package swr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLDatabase is the on disk [Database], one yaml file per thing under ./data and ./docs.
+//
+//	data/areas/<name>.yml
+//	data/mobs/<area>/<mob>.yml
+//	data/items/<area>/<item>.yml
+//	data/ships/<name>.yml
+//	data/ships/prototypes/<type>.yml
+//	data/accounts/<n>/<name>.yml
+//	docs/<help>.yml
+type YAMLDatabase struct{}
+
+func (y *YAMLDatabase) Name() string {
+	return DATABASE_YAML
+}
+
+// read_yaml unmarshals the yaml file at path into v.
+func read_yaml(path string, v interface{}) error {
+	fp, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(fp, v); err != nil {
+		return Err("unable to read %s: %v", path, err)
+	}
+	return nil
+}
+
+// yaml_files returns every .yml/.yaml file under dir, skip is a path fragment to leave out.
+func yaml_files(dir string, skip string) ([]string, error) {
+	ret := make([]string, 0)
+	err := filepath.Walk(dir,
+		func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			if skip != "" && strings.Contains(path, skip) {
+				return nil
+			}
+			if strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml") {
+				ret = append(ret, path)
+			}
+			return nil
+		})
+	return ret, err
+}
+
+func yaml_ship_filename(ship *ShipData) string {
+	return sprintf("data/ships/%s.yml", strings.ToLower(strings.ReplaceAll(ship.Name, " ", "_")))
+}
+
+// Prototypes keep the file they were loaded from, new ones are named after their type.
+func yaml_ship_prototype_filename(ship *ShipData) string {
+	if ship.Filename != "" {
+		return ship.Filename
+	}
+	return sprintf("data/ships/prototypes/%s.yml", strings.ToLower(strings.ReplaceAll(ship.Type, " ", "_")))
+}
+
+func yaml_player_filename(name string) string {
+	name = strings.ToLower(name)
+	return fmt.Sprintf("data/accounts/%s/%s.yml", name[0:1], name)
+}
+
+func (y *YAMLDatabase) LoadAreas() ([]*AreaData, error) {
+	files, err := yaml_files("data/areas", "")
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*AreaData, 0, len(files))
+	for _, path := range files {
+		area, err := y.LoadArea(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+		if err != nil {
+			ErrorCheck(err)
+			continue
+		}
+		ret = append(ret, area)
+	}
+	return ret, nil
+}
+
+func (y *YAMLDatabase) LoadArea(name string) (*AreaData, error) {
+	area := new(AreaData)
+	if err := read_yaml(sprintf("data/areas/%s.yml", name), area); err != nil {
+		return nil, err
+	}
+	return area, nil
+}
+
+func (y *YAMLDatabase) SaveArea(area *AreaData) error {
+	return write_yaml(sprintf("data/areas/%s.yml", area.Name), area)
+}
+
+func (y *YAMLDatabase) DeleteArea(area *AreaData) error {
+	return os.Remove(sprintf("data/areas/%s.yml", area.Name))
+}
+
+func (y *YAMLDatabase) LoadMobs() ([]*CharData, error) {
+	files, err := yaml_files("data/mobs", "")
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*CharData, 0, len(files))
+	for _, path := range files {
+		ch := new(CharData)
+		if err := read_yaml(path, ch); err != nil {
+			ErrorCheck(err)
+			continue
+		}
+		ch.Filename = path
+		ret = append(ret, ch)
+	}
+	return ret, nil
+}
+
+func (y *YAMLDatabase) SaveMob(mob *CharData) error {
+	if mob.Filename == "" {
+		mob.Filename = sprintf("data/mobs/%d.yml", mob.Id)
+	}
+	return write_yaml(mob.Filename, mob)
+}
+
+func (y *YAMLDatabase) DeleteMob(mob *CharData) error {
+	return os.Remove(mob.Filename)
+}
+
+func (y *YAMLDatabase) LoadItems() ([]*ItemData, error) {
+	files, err := yaml_files("data/items", "")
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*ItemData, 0, len(files))
+	for _, path := range files {
+		item := new(ItemData)
+		if err := read_yaml(path, item); err != nil {
+			ErrorCheck(err)
+			continue
+		}
+		item.Filename = path
+		ret = append(ret, item)
+	}
+	return ret, nil
+}
+
+func (y *YAMLDatabase) SaveItem(item *ItemData) error {
+	if item.Filename == "" {
+		item.Filename = sprintf("data/items/%d.yml", item.Id)
+	}
+	return write_yaml(item.Filename, item)
+}
+
+func (y *YAMLDatabase) LoadShips() ([]*ShipData, error) {
+	files, err := yaml_files("data/ships", "prototype")
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*ShipData, 0, len(files))
+	for _, path := range files {
+		ship := new(ShipData)
+		if err := read_yaml(path, ship); err != nil {
+			ErrorCheck(err)
+			continue
+		}
+		ship.Filename = path
+		ret = append(ret, ship)
+	}
+	return ret, nil
+}
+
+func (y *YAMLDatabase) SaveShip(ship *ShipData) error {
+	return write_yaml(yaml_ship_filename(ship), ship)
+}
+
+func (y *YAMLDatabase) DeleteShip(ship *ShipData) error {
+	return os.Remove(yaml_ship_filename(ship))
+}
+
+func (y *YAMLDatabase) LoadShipPrototypes() ([]*ShipData, error) {
+	files, err := yaml_files("data/ships/prototypes", "")
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*ShipData, 0, len(files))
+	for _, path := range files {
+		ship := new(ShipData)
+		if err := read_yaml(path, ship); err != nil {
+			ErrorCheck(err)
+			continue
+		}
+		ship.Filename = path
+		ret = append(ret, ship)
+	}
+	return ret, nil
+}
+
+func (y *YAMLDatabase) SaveShipPrototype(ship *ShipData) error {
+	return write_yaml(yaml_ship_prototype_filename(ship), ship)
+}
+
+func (y *YAMLDatabase) DeleteShipPrototype(ship *ShipData) error {
+	return os.Remove(yaml_ship_prototype_filename(ship))
+}
+
+func (y *YAMLDatabase) PlayerExists(name string) bool {
+	if name == "" {
+		return false
+	}
+	return file_exists(yaml_player_filename(name))
+}
+
+func (y *YAMLDatabase) LoadPlayer(name string) (*PlayerProfile, error) {
+	if name == "" {
+		return nil, Err("no player name given")
+	}
+	player := new(PlayerProfile)
+	if err := read_yaml(yaml_player_filename(name), player); err != nil {
+		return nil, err
+	}
+	return player, nil
+}
+
+func (y *YAMLDatabase) LoadPlayers() ([]*PlayerProfile, error) {
+	files, err := yaml_files("data/accounts", "")
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*PlayerProfile, 0, len(files))
+	for _, path := range files {
+		player := new(PlayerProfile)
+		if err := read_yaml(path, player); err != nil {
+			ErrorCheck(err)
+			continue
+		}
+		ret = append(ret, player)
+	}
+	return ret, nil
+}
+
+func (y *YAMLDatabase) SavePlayer(player *PlayerProfile) error {
+	return write_yaml(yaml_player_filename(player.Char.Name), player)
+}
+
+func (y *YAMLDatabase) LoadHelps() ([]*HelpData, error) {
+	files, err := yaml_files("docs", "")
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*HelpData, 0, len(files))
+	for _, path := range files {
+		help := new(HelpData)
+		if err := read_yaml(path, help); err != nil {
+			ErrorCheck(err)
+			continue
+		}
+		help.Filename = filepath.Base(path)
+		ret = append(ret, help)
+	}
+	return ret, nil
+}
+
+func (y *YAMLDatabase) SaveHelp(help *HelpData) error {
+	if help.Filename == "" {
+		help.Filename = sprintf("%s.yml", strings.ToLower(strings.ReplaceAll(help.Name, " ", "_")))
+	}
+	return write_yaml(sprintf("docs/%s", help.Filename), help)
+}
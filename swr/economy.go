@@ -30,7 +30,7 @@ func mineBlockForEntity(entity Entity) {
 		result := sprintf("%x", sha256.Sum256([]byte(sprintf("%x", random_float()))))
 		if strings.EqualFold(result[:MINER_DIFFICULTY], strings.Repeat("0", MINER_DIFFICULTY)) {
 			entity.GetCharData().Bank += 10000
-			DB().SavePlayerData(entity.(*PlayerProfile))
+			ErrorCheck(DB().SavePlayerData(entity.(*PlayerProfile)))
 			entity.Send("\r\n}YYou have won the lottery. You have been awarded &W%d&Y credits!&d\r\n", 10000)
 			entity.Send("%s", result)
 			MINER_DIFFICULTY += 2
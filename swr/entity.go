@@ -112,6 +112,7 @@ type CharData struct {
 	Flags     []string             `yaml:"flags,omitempty"`         // list of flags. See [entity_flags] for values.
 	AI        Brain                `yaml:"-"`                       // actual AI interface. instantiated upon spawn.
 	Attacker  Entity               `yaml:"-"`                       // who is this mob fighting?
+	Home      uint                 `yaml:"-"`                       // room the mob was spawned in, sentinels walk back to it.
 }
 
 // Returns true if the entity is a *PlayerProfile, false if just a *CharData mob.
@@ -277,21 +278,9 @@ func (c *CharData) Weapon() Item {
 }
 
 // Find an item on this entity by keyword. Useful for checking existence of keys or player commands on items.
+// Supports numbered targets like 2.blaster, see [target_parse].
 func (c *CharData) FindItem(keyword string) Item {
-	for i := range c.Inventory {
-		item := c.Inventory[i]
-		if item == nil {
-			continue
-		}
-		keys := item.GetKeywords()
-		for k := range keys {
-			key := keys[k]
-			if strings.HasPrefix(key, keyword) {
-				return item
-			}
-		}
-	}
-	return nil
+	return target_item(target_inventory(c), keyword)
 }
 
 // Remove an item from this person. No soup for you.
@@ -374,6 +363,7 @@ type PlayerProfile struct {
 	Frequency   string    `yaml:"freq"`
 	Kills       uint      `yaml:"kills"`
 	PKills      uint      `yaml:"pkills"`
+	Logged      bool      `yaml:"logged,omitempty"` // every command is written to the server log, see do_log.
 	Client      Client    `yaml:"-" gorm:"-"`
 	NeedPrompt  bool      `yaml:"-" gorm:"-"`
 	LastCommand string    `yaml:"-" gorm:"-"`
@@ -587,6 +577,9 @@ func player_prompt(player *PlayerProfile) string {
 	prompt := "\r\n"
 	prompt += fmt.Sprintf("&Y[&GHp:&W%d&Y/&G%d&Y]&d ", player.CurrentHp(), player.MaxHp())
 	prompt += fmt.Sprintf("&Y[&GMv:&W%d&Y/&G%d&Y]&d ", player.CurrentMv(), player.MaxMv())
+	if ship := player.GetShip(); ship != nil && ship.GetData().InHyper {
+		prompt += fmt.Sprintf("&Y[&CHyperspace:&W%ds&Y]&d ", ship.GetData().HyperTimeUntil)
+	}
 	if player.IsFighting() {
 		attacker := player.Char.Attacker
 		hp := attacker.MaxHp()
@@ -778,12 +771,27 @@ func entity_unspeakable_state(entity Entity) bool {
 	}
 	state := entity.GetCharData().State
 	switch state {
+	case ENTITY_STATE_DEAD, ENTITY_STATE_UNCONSCIOUS, ENTITY_STATE_SLEEPING:
+		return true
+	}
+	return false
+}
+
+// entity_position maps the entity's state to a POSITION_* for command position checks.
+func entity_position(entity Entity) string {
+	switch entity.GetCharData().State {
 	case ENTITY_STATE_DEAD:
+		return POSITION_DEAD
 	case ENTITY_STATE_UNCONSCIOUS:
+		return POSITION_UNCONSCIOUS
 	case ENTITY_STATE_SLEEPING:
-		return true
+		return POSITION_SLEEPING
+	case ENTITY_STATE_SITTING, ENTITY_STATE_SEDATED:
+		return POSITION_SITTING
+	case ENTITY_STATE_FIGHTING:
+		return POSITION_FIGHTING
 	}
-	return false
+	return POSITION_STANDING
 }
 
 // Why can't they speak? (or see? (or breathe?)). Returns the reason an entity is in an unspeakable state.
@@ -815,10 +823,21 @@ func entity_pickup_item(entity Entity, item Item) bool {
 		entity.Send("\r\n&RYou can't carry any more items!&d\r\n")
 		return false
 	}
+	item_clear_decay(item)
 	ch.Inventory = append(ch.Inventory, item.GetData())
 	return true
 }
 
+// Returns true if the entity has the flag.
+func entity_has_flag(ch *CharData, flag string) bool {
+	for _, f := range ch.Flags {
+		if strings.EqualFold(f, flag) {
+			return true
+		}
+	}
+	return false
+}
+
 // Returns a 0-100 skill value for a skill.
 func entity_get_skill_value(ch *CharData, skill string) int {
 	if v, ok := ch.Skills[strings.ToLower(skill)]; ok {
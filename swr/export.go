@@ -0,0 +1,587 @@
+/*  Star Wars Role-Playing Mud
+ *  Copyright (C) 2022 @{See Authors}
+ *
+ *  This program is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  This program is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *  GNU General Public License for more details.
+ *
+ *  You should have received a copy of the GNU General Public License
+ *  along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+package swr
+
+import (
+	"encoding/xml"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Sizes of the exported SVG, in pixels.
+const (
+	EXPORT_CELL_W = 190 // room to room, across.
+	EXPORT_CELL_H = 100 // room to room, down.
+	EXPORT_ROOM_W = 150
+	EXPORT_ROOM_H = 50
+	EXPORT_MARGIN = 40
+)
+
+// Colours the exports use for exits and rooms.
+const (
+	EXPORT_COLOR_EXIT    = "#555555"
+	EXPORT_COLOR_ONE_WAY = "#d35400"
+	EXPORT_COLOR_DOOR    = "#2471a3"
+	EXPORT_COLOR_LOCKED  = "#c0392b"
+	EXPORT_COLOR_ROOM    = "#ffffff"
+	EXPORT_COLOR_SPAWN   = "#fdebd0"
+)
+
+// export_graph is the rooms, exits and spawns of the areas being exported.
+type export_graph struct {
+	areas   []*AreaData
+	rooms   map[uint]*RoomData
+	area_of map[uint]*AreaData
+	spawns  map[uint][]string // what spawns in each room, "mob: a male citizen".
+	edges   []export_edge
+}
+
+// export_edge is an exit between two rooms, or both exits if they lead to each other.
+type export_edge struct {
+	from    uint
+	to      uint
+	label   string
+	one_way bool
+	door    string // "" if there's no door, otherwise a DOOR_* state.
+	hidden  bool
+}
+
+// Export writes a Graphviz DOT file and an SVG of the area named name, or of the whole galaxy if name is "all",
+// into dir. It reads the world straight from the database, the game doesn't need to be running.
+func Export(name string, dir string) error {
+	store, err := database_open_config()
+	if err != nil {
+		return err
+	}
+	areas, err := store.LoadAreas()
+	if err != nil {
+		return err
+	}
+	mobs := make(map[uint]string)
+	if list, err := store.LoadMobs(); err == nil {
+		for _, m := range list {
+			mobs[m.Id] = m.Name
+		}
+	}
+	items := make(map[uint]string)
+	if list, err := store.LoadItems(); err == nil {
+		for _, i := range list {
+			items[i.Id] = i.Name
+		}
+	}
+	files, err := export_world(areas, mobs, items, name, dir)
+	for _, f := range files {
+		log.Printf("Exported %s", f)
+	}
+	return err
+}
+
+// export_live_names returns the names of the mob and item prototypes in the game, for exporting from inside it.
+func export_live_names() (map[uint]string, map[uint]string) {
+	d := DB()
+	d.Lock()
+	defer d.Unlock()
+	mobs := make(map[uint]string)
+	for id, m := range d.mobs {
+		mobs[id] = m.Name
+	}
+	items := make(map[uint]string)
+	for id, i := range d.items {
+		items[id] = i.Name
+	}
+	return mobs, items
+}
+
+// export_world exports the areas (just the one named name unless it's "all") and returns the files written.
+func export_world(areas []*AreaData, mobs map[uint]string, items map[uint]string, name string, dir string) ([]string, error) {
+	selected := make([]*AreaData, 0)
+	for _, area := range areas {
+		if strings.EqualFold(name, "all") || strings.EqualFold(area.Name, name) {
+			selected = append(selected, area)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, Err("there's no area called %s", name)
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].Name < selected[j].Name })
+	g := export_build(selected, mobs, items)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	base := "galaxy"
+	if !strings.EqualFold(name, "all") {
+		base = strings.ToLower(selected[0].Name)
+	}
+	dot := filepath.Join(dir, base+".dot")
+	if err := os.WriteFile(dot, []byte(g.dot(base)), 0644); err != nil {
+		return nil, err
+	}
+	svg := filepath.Join(dir, base+".svg")
+	if err := os.WriteFile(svg, []byte(g.svg(base)), 0644); err != nil {
+		return []string{dot}, err
+	}
+	return []string{dot, svg}, nil
+}
+
+// export_build gathers up the rooms, exits and spawns of the areas.
+func export_build(areas []*AreaData, mobs map[uint]string, items map[uint]string) *export_graph {
+	g := &export_graph{
+		areas:   areas,
+		rooms:   make(map[uint]*RoomData),
+		area_of: make(map[uint]*AreaData),
+		spawns:  make(map[uint][]string),
+		edges:   make([]export_edge, 0),
+	}
+	doors := make(map[string]string)
+	for _, area := range areas {
+		for i := range area.Rooms {
+			g.rooms[area.Rooms[i].Id] = &area.Rooms[i]
+			g.area_of[area.Rooms[i].Id] = area
+		}
+		for _, d := range area.Doors {
+			if d.Ship == 0 {
+				doors[sprintf("%d %s", d.Room, d.Direction)] = d.State
+			}
+		}
+		// ship spawns are in ship rooms, which aren't part of the area's map.
+		for _, s := range area.Mobs {
+			if s.Ship == 0 {
+				g.spawns[s.Room] = append(g.spawns[s.Room], "mob: "+export_name(mobs, s.Mob))
+			}
+		}
+		for _, s := range area.Items {
+			if s.Ship == 0 {
+				g.spawns[s.Room] = append(g.spawns[s.Room], "item: "+export_name(items, s.Item))
+			}
+		}
+	}
+	// the state a door starts in, its door reset if it has one.
+	door_state := func(room *RoomData, dir string) string {
+		f := room.GetExitFlags(dir)
+		if f == nil {
+			return ""
+		}
+		if s, ok := doors[sprintf("%d %s", room.Id, dir)]; ok {
+			return s
+		}
+		if f.Locked {
+			return DOOR_LOCKED
+		}
+		if f.Closed {
+			return DOOR_CLOSED
+		}
+		return DOOR_OPEN
+	}
+	for _, id := range g.room_ids() {
+		room := g.rooms[id]
+		for _, exit := range room.GetExits() {
+			dir := exit.GetDirection()
+			to_id := room.Exits[dir]
+			e := export_edge{from: id, to: to_id, label: dir, door: door_state(room, dir), hidden: exit.IsHidden()}
+			if f := room.GetExitFlags(dir); f != nil && f.Key != 0 {
+				e.label += sprintf(" (key %d)", f.Key)
+			}
+			if to, ok := g.rooms[to_id]; ok {
+				back := ""
+				for _, d := range directions {
+					if to.Exits[d] == id {
+						back = d
+					}
+				}
+				for d, tid := range to.Exits {
+					if back == "" && tid == id {
+						back = d
+					}
+				}
+				if back == "" {
+					e.one_way = true
+				} else if to_id < id || (to_id == id && back < dir) {
+					continue // drawn from the other side.
+				} else {
+					e.label += "/" + back
+					if s := door_state(to, back); export_door_rank(s) > export_door_rank(e.door) {
+						e.door = s
+					}
+				}
+			}
+			g.edges = append(g.edges, e)
+		}
+	}
+	return g
+}
+
+// room_ids returns the ids of the exported rooms in order.
+func (g *export_graph) room_ids() []uint {
+	ret := make([]uint, 0, len(g.rooms))
+	for id := range g.rooms {
+		ret = append(ret, id)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i] < ret[j] })
+	return ret
+}
+
+// export_door_rank orders door states so the shut side of a door wins.
+func export_door_rank(state string) int {
+	switch state {
+	case DOOR_OPEN:
+		return 1
+	case DOOR_CLOSED:
+		return 2
+	case DOOR_LOCKED:
+		return 3
+	}
+	return 0
+}
+
+// export_name returns the name of the mob or item, or its vnum if it doesn't exist.
+func export_name(names map[uint]string, id uint) string {
+	if n, ok := names[id]; ok {
+		return n
+	}
+	return sprintf("%d (missing)", id)
+}
+
+// style returns the colour, dash pattern and a note for how an exit is drawn.
+func (e export_edge) style() (color string, dash string, note string) {
+	color = EXPORT_COLOR_EXIT
+	switch e.door {
+	case DOOR_OPEN:
+		color, note = EXPORT_COLOR_DOOR, "open door"
+	case DOOR_CLOSED:
+		color, dash, note = EXPORT_COLOR_DOOR, "6,4", "closed door"
+	case DOOR_LOCKED:
+		color, dash, note = EXPORT_COLOR_LOCKED, "6,4", "locked door"
+	}
+	if e.hidden {
+		dash, note = "2,3", strings.TrimSpace("hidden "+note)
+	}
+	if e.one_way {
+		color, note = EXPORT_COLOR_ONE_WAY, strings.TrimSpace("one way "+note)
+	}
+	return
+}
+
+// dot_quote quotes a string for a DOT file.
+func dot_quote(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, "\"", "\\\"")
+	s = strings.ReplaceAll(s, "\n", "\\n")
+	return "\"" + s + "\""
+}
+
+// dot returns the graph in Graphviz's DOT language, each area in its own cluster.
+func (g *export_graph) dot(name string) string {
+	var b strings.Builder
+	b.WriteString(sprintf("digraph %s {\n", dot_quote(name)))
+	b.WriteString("\tgraph [overlap=false, splines=true, fontname=\"Helvetica\"];\n")
+	b.WriteString(sprintf("\tnode [shape=box, style=\"rounded,filled\", fillcolor=\"%s\", fontname=\"Helvetica\", fontsize=10];\n", EXPORT_COLOR_ROOM))
+	b.WriteString("\tedge [fontname=\"Helvetica\", fontsize=8, arrowsize=0.6];\n")
+	for _, area := range g.areas {
+		b.WriteString(sprintf("\tsubgraph %s {\n", dot_quote("cluster_"+area.Name)))
+		label := area.Name
+		if r := area.GetVnums(VNUM_ROOM); r != nil {
+			label += sprintf(" [%d-%d]", r[0], r[1])
+		}
+		b.WriteString(sprintf("\t\tlabel=%s;\n", dot_quote(label)))
+		for i := range area.Rooms {
+			room := &area.Rooms[i]
+			label := sprintf("%d\n%s", room.Id, room.Name)
+			fill := ""
+			if spawns := g.spawns[room.Id]; len(spawns) > 0 {
+				label += "\n" + strings.Join(spawns, "\n")
+				fill = sprintf(", fillcolor=\"%s\"", EXPORT_COLOR_SPAWN)
+			}
+			b.WriteString(sprintf("\t\tr%d [label=%s%s];\n", room.Id, dot_quote(label), fill))
+		}
+		b.WriteString("\t}\n")
+	}
+	outside := make(map[uint]bool)
+	for _, e := range g.edges {
+		if _, ok := g.rooms[e.to]; !ok && !outside[e.to] {
+			outside[e.to] = true
+			b.WriteString(sprintf("\tr%d [label=%s, style=\"rounded,dashed\"];\n", e.to, dot_quote(sprintf("%d\n(elsewhere)", e.to))))
+		}
+	}
+	for _, e := range g.edges {
+		color, dash, note := e.style()
+		label := e.label
+		if note != "" {
+			label += "\n" + note
+		}
+		attrs := sprintf("label=%s, color=\"%s\", fontcolor=\"%s\"", dot_quote(label), color, color)
+		if !e.one_way {
+			attrs += ", dir=none"
+		} else {
+			attrs += ", penwidth=2"
+		}
+		if e.hidden {
+			attrs += ", style=dotted"
+		} else if dash != "" {
+			attrs += ", style=dashed"
+		}
+		b.WriteString(sprintf("\tr%d -> r%d [%s];\n", e.from, e.to, attrs))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// EXPORT_ROW_CELLS is how wide a row of separate parts of an area gets before the next row starts.
+const EXPORT_ROW_CELLS = 30
+
+// export_layout places an area's rooms on a grid, where their coords say if they have them, otherwise from the
+// room they were reached from. Stairs up go up and right, down goes down and left. A room that would land on
+// another is moved to the nearest free spot. Parts of the area that aren't joined to the rest, and don't have
+// coords to say where they go, are packed in rows underneath.
+func export_layout(area *AreaData) map[uint][2]int {
+	rooms := make(map[uint]*RoomData)
+	ids := make([]uint, 0, len(area.Rooms))
+	for i := range area.Rooms {
+		rooms[area.Rooms[i].Id] = &area.Rooms[i]
+		ids = append(ids, area.Rooms[i].Id)
+	}
+	// rooms with coords go first, the packed parts go under them.
+	sort.Slice(ids, func(i, j int) bool {
+		_, ci := room_coords(rooms[ids[i]])
+		_, cj := room_coords(rooms[ids[j]])
+		if ci != cj {
+			return ci
+		}
+		return ids[i] < ids[j]
+	})
+	pos := make(map[uint][2]int)
+	taken := make(map[[2]int]bool)
+	flat := func(c [3]int) [2]int {
+		return [2]int{c[0] + c[2], c[1] - c[2]}
+	}
+	// walk lays out the part of the area joined to seed, starting at start, in the taken spots.
+	walk := func(seed uint, start [2]int, taken map[[2]int]bool) map[uint][2]int {
+		part := make(map[uint][2]int)
+		place := func(id uint, p [2]int) {
+			for r := 1; taken[p]; r++ {
+				// walk out in rings until there's a free spot.
+				found := false
+				for dy := -r; dy <= r && !found; dy++ {
+					for dx := -r; dx <= r && !found; dx++ {
+						if q := [2]int{p[0] + dx, p[1] + dy}; !taken[q] {
+							p, found = q, true
+						}
+					}
+				}
+			}
+			part[id] = p
+			taken[p] = true
+		}
+		place(seed, start)
+		queue := []uint{seed}
+		for len(queue) > 0 {
+			room := rooms[queue[0]]
+			queue = queue[1:]
+			from, has_from := room_coords(room)
+			for _, exit := range room.GetExits() {
+				id := room.Exits[exit.GetDirection()]
+				to, ok := rooms[id]
+				if !ok {
+					continue
+				}
+				if _, ok := pos[id]; ok {
+					continue
+				}
+				if _, ok := part[id]; ok {
+					continue
+				}
+				delta, ok := map_deltas[exit.GetDirection()]
+				if !ok {
+					delta = [3]int{1, 1, 0}
+				}
+				if c, ok := room_coords(to); ok && has_from {
+					delta = [3]int{c[0] - from[0], c[1] - from[1], c[2] - from[2]}
+				}
+				d := flat(delta)
+				p := part[room.Id]
+				place(id, [2]int{p[0] + d[0], p[1] + d[1]})
+				queue = append(queue, id)
+			}
+		}
+		return part
+	}
+	row_y, row_x, row_bottom := 0, 0, -2
+	for _, seed := range ids {
+		if _, ok := pos[seed]; ok {
+			continue
+		}
+		if c, ok := room_coords(rooms[seed]); ok {
+			for id, p := range walk(seed, flat(c), taken) {
+				pos[id] = p
+				if p[1] > row_bottom {
+					row_bottom = p[1]
+				}
+			}
+			continue
+		}
+		if row_x == 0 {
+			row_y = row_bottom + 2
+		}
+		// lay the part out on its own, then move it to the next spot in the row.
+		part := walk(seed, [2]int{0, 0}, make(map[[2]int]bool))
+		min_x, min_y, max_x, max_y := 0, 0, 0, 0
+		for _, p := range part {
+			min_x, min_y = min(min_x, p[0]), min(min_y, p[1])
+			max_x, max_y = max(max_x, p[0]), max(max_y, p[1])
+		}
+		if row_x > 0 && row_x+max_x-min_x >= EXPORT_ROW_CELLS {
+			row_x, row_y = 0, row_bottom+2
+		}
+		for id, p := range part {
+			p = [2]int{p[0] - min_x + row_x, p[1] - min_y + row_y}
+			pos[id] = p
+			taken[p] = true
+		}
+		row_x += max_x - min_x + 2
+		row_bottom = max(row_bottom, row_y+max_y-min_y)
+		if row_x >= EXPORT_ROW_CELLS {
+			row_x = 0
+		}
+	}
+	return pos
+}
+
+// svg_escape escapes text for an SVG file.
+func svg_escape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// svg draws the graph, each area a panel below the one before it.
+func (g *export_graph) svg(name string) string {
+	var body strings.Builder
+	width, top := 0, EXPORT_MARGIN
+	centers := make(map[uint][2]int)
+	type panel struct {
+		area  *AreaData
+		top   int
+		min_x int
+		min_y int
+		pos   map[uint][2]int
+	}
+	panels := make([]panel, 0, len(g.areas))
+	for _, area := range g.areas {
+		pos := export_layout(area)
+		min_x, min_y, max_x, max_y := 0, 0, 0, 0
+		first := true
+		for _, p := range pos {
+			if first || p[0] < min_x {
+				min_x = p[0]
+			}
+			if first || p[1] < min_y {
+				min_y = p[1]
+			}
+			if first || p[0] > max_x {
+				max_x = p[0]
+			}
+			if first || p[1] > max_y {
+				max_y = p[1]
+			}
+			first = false
+		}
+		panels = append(panels, panel{area: area, top: top, min_x: min_x, min_y: min_y, pos: pos})
+		for id, p := range pos {
+			centers[id] = [2]int{
+				EXPORT_MARGIN + (p[0]-min_x)*EXPORT_CELL_W + EXPORT_ROOM_W/2,
+				top + 30 + (p[1]-min_y)*EXPORT_CELL_H + EXPORT_ROOM_H/2,
+			}
+		}
+		if w := EXPORT_MARGIN*2 + (max_x-min_x)*EXPORT_CELL_W + EXPORT_ROOM_W; w > width {
+			width = w
+		}
+		top += 30 + (max_y-min_y)*EXPORT_CELL_H + EXPORT_ROOM_H + EXPORT_MARGIN*2
+	}
+	for _, p := range panels {
+		label := p.area.Name
+		if r := p.area.GetVnums(VNUM_ROOM); r != nil {
+			label += sprintf(" [%d-%d]", r[0], r[1])
+		}
+		body.WriteString(sprintf("<text x=\"%d\" y=\"%d\" class=\"area\">%s</text>\n", EXPORT_MARGIN, p.top+16, svg_escape(label)))
+	}
+	for _, e := range g.edges {
+		color, dash, note := e.style()
+		a, ok := centers[e.from]
+		if !ok {
+			continue
+		}
+		title := sprintf("%d %s", e.from, e.label)
+		if note != "" {
+			title += ", " + note
+		}
+		b, ok := centers[e.to]
+		if !ok {
+			// leads out of what's being exported, draw a stub with where it goes.
+			b = [2]int{a[0] + EXPORT_ROOM_W/2 + 20, a[1] - EXPORT_ROOM_H/2 - 10}
+			body.WriteString(sprintf("<text x=\"%d\" y=\"%d\" class=\"out\">to %d</text>\n", b[0]+2, b[1], e.to))
+		}
+		attrs := sprintf("stroke=\"%s\"", color)
+		if dash != "" {
+			attrs += sprintf(" stroke-dasharray=\"%s\"", dash)
+		}
+		if e.one_way {
+			attrs += " stroke-width=\"2\" marker-end=\"url(#arrow)\""
+		}
+		body.WriteString(sprintf("<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" %s><title>%s</title></line>\n",
+			a[0], a[1], b[0], b[1], attrs, svg_escape(title)))
+	}
+	for _, id := range g.room_ids() {
+		c, ok := centers[id]
+		if !ok {
+			continue
+		}
+		room := g.rooms[id]
+		spawns := g.spawns[id]
+		fill := EXPORT_COLOR_ROOM
+		if len(spawns) > 0 {
+			fill = EXPORT_COLOR_SPAWN
+		}
+		title := sprintf("%d %s", id, room.Name)
+		if len(spawns) > 0 {
+			title += "\n" + strings.Join(spawns, "\n")
+		}
+		x, y := c[0]-EXPORT_ROOM_W/2, c[1]-EXPORT_ROOM_H/2
+		body.WriteString(sprintf("<g><title>%s</title>", svg_escape(title)))
+		body.WriteString(sprintf("<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" rx=\"6\" fill=\"%s\"/>", x, y, EXPORT_ROOM_W, EXPORT_ROOM_H, fill))
+		body.WriteString(sprintf("<text x=\"%d\" y=\"%d\" class=\"id\">%d</text>", c[0], y+14, id))
+		body.WriteString(sprintf("<text x=\"%d\" y=\"%d\">%s</text>", c[0], y+28, svg_escape(tstring(room.Name, 24))))
+		if len(spawns) > 0 {
+			body.WriteString(sprintf("<text x=\"%d\" y=\"%d\" class=\"spawn\">%d spawns</text>", c[0], y+42, len(spawns)))
+		}
+		body.WriteString("</g>\n")
+	}
+	var b strings.Builder
+	b.WriteString(sprintf("<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", width, top, width, top))
+	b.WriteString(sprintf("<title>%s</title>\n", svg_escape(name)))
+	b.WriteString("<defs><marker id=\"arrow\" viewBox=\"0 0 10 10\" refX=\"10\" refY=\"5\" markerWidth=\"6\" markerHeight=\"6\" orient=\"auto-start-reverse\">")
+	b.WriteString(sprintf("<path d=\"M 0 0 L 10 5 L 0 10 z\" fill=\"%s\"/></marker></defs>\n", EXPORT_COLOR_ONE_WAY))
+	b.WriteString("<style>text{font-family:Helvetica,sans-serif;font-size:11px;text-anchor:middle}" +
+		" rect{stroke:#333333} .id{font-weight:bold} .spawn{fill:#9a5b13;font-size:9px}" +
+		" .area{font-size:16px;font-weight:bold;text-anchor:start} .out{font-size:9px;text-anchor:start;fill:#777777}</style>\n")
+	b.WriteString(sprintf("<rect x=\"0\" y=\"0\" width=\"%d\" height=\"%d\" fill=\"#fafafa\" stroke=\"none\"/>\n", width, top))
+	b.WriteString(body.String())
+	b.WriteString("</svg>\n")
+	return b.String()
+}
@@ -41,10 +41,6 @@ func do_fight(entity Entity, args ...string) {
 			entity.Send("\r\n&RYou can't fight while working!&d\r\n")
 			return
 		}
-		if state == ENTITY_STATE_DEAD {
-			entity.Send("\r\n&RYou are dead!&d\r\n")
-			return
-		}
 		if state == ENTITY_STATE_GUNNING {
 			entity.Send("\r\n&RYou can't gun and fight at the same time!&d\r\n")
 			return
@@ -53,47 +49,31 @@ func do_fight(entity Entity, args ...string) {
 			entity.Send("\r\n&RYou can't fly and fight at the same time!&d\r\n")
 			return
 		}
-		if state == ENTITY_STATE_SEDATED {
-			entity.Send("\r\nYou feel too relaxed!\r\n")
+		room := entity.GetRoom()
+		e := target_entity(entity, room.GetEntities(), args[0])
+		if e == nil {
+			entity.Send("\r\n&dThey aren't here.\r\n")
 			return
 		}
-		if state == ENTITY_STATE_SLEEPING {
-			entity.Send("\r\nYou are asleep!\r\n")
+		if e == entity {
+			entity.Send("\r\n&RYou can't fight yourself.&d\r\n")
 			return
 		}
-		if state == ENTITY_STATE_UNCONSCIOUS {
-			entity.Send("\r\n&RYou are unconscious!&d\r\n")
+		if room.IsFlagged(ROOM_FLAG_SAFE) {
+			entity.Send("\r\n&YThis is a safe place, you can't fight here.&d\r\n")
 			return
 		}
-		found := false
-		for _, e := range entity.GetRoom().GetEntities() {
-			if e == nil {
-				continue
-			}
-			if e == entity {
-				continue
-			}
-			ch := e.GetCharData()
-			for _, k := range ch.Keywords {
-				if strings.HasPrefix(strings.ToLower(k), strings.ToLower(args[0])) {
-					found = true
-					if ch.State != ENTITY_STATE_DEAD && ch.State != ENTITY_STATE_UNCONSCIOUS {
-						e.SetAttacker(entity)
-						entity.SetAttacker(e)
-						entity.Send("\r\n&RYou begin fighting &w%s&R!!&d\r\n", ch.Name)
-						break
-					} else {
-						entity.Send("\r\n&RYou can't fight what can't fight back.&d\r\n")
-					}
-
-				}
-			}
-			if found {
-				break
-			}
+		if !room_can_fight(room, entity, e) {
+			entity.Send("\r\n&YYou can't fight other players here.&d\r\n")
+			return
 		}
-		if !found {
-			entity.Send("\r\n&dThey aren't here.\r\n")
+		ch := e.GetCharData()
+		if ch.State != ENTITY_STATE_DEAD && ch.State != ENTITY_STATE_UNCONSCIOUS {
+			e.SetAttacker(entity)
+			entity.SetAttacker(e)
+			entity.Send("\r\n&RYou begin fighting &w%s&R!!&d\r\n", ch.Name)
+		} else {
+			entity.Send("\r\n&RYou can't fight what can't fight back.&d\r\n")
 		}
 	}
 }
@@ -105,6 +85,14 @@ func processCombat() {
 		if e != nil {
 			if e.IsFighting() {
 				target := e.GetCharData().Attacker
+				// a fight that ends up somewhere it isn't allowed, like a mob prog's kill() in a safe room, is broken up.
+				if target != nil && target.RoomId() == e.RoomId() && target.ShipId() == e.ShipId() {
+					if room := e.GetRoom(); room != nil && !room_can_fight(room, e, target) {
+						e.StopFighting()
+						target.StopFighting()
+						continue
+					}
+				}
 				do_combat(e, target)
 			}
 		}
@@ -133,6 +121,14 @@ func do_combat(attacker Entity, defender Entity) {
 		if defender.IsPlayer() {
 			defender.Send("\r\nYou stop fighting &d%s&d as they are no longer here.\r\n", ach.Name)
 		}
+		// hunters go after whoever got away from them.
+		if b, ok := ach.AI.(*GenericBrain); ok && entity_has_flag(ach, "hunter") {
+			b.Hunt(defender)
+		}
+		if b, ok := dch.AI.(*GenericBrain); ok && entity_has_flag(dch, "hunter") {
+			b.Hunt(attacker)
+		}
+		return
 	}
 	for _, flag := range ach.Flags {
 		if flag == "nofight" {
@@ -286,11 +282,12 @@ func make_corpse(entity Entity) {
 		}
 		corpse.Keywords = append(corpse.Keywords, "corpse")
 		corpse.Items = items
+		item_set_decay(corpse)
 		room := ch.GetRoom()
 		room.AddItem(corpse)
 		if entity.IsPlayer() {
 			entity.Send("\r\n&R %s You have been killed. %s&d\r\n\r\n\r\n", EMOJI_SKULL, EMOJI_SKULL)
-			DB().SavePlayerData(entity.(*PlayerProfile))
+			ErrorCheck(DB().SavePlayerData(entity.(*PlayerProfile)))
 			return
 		} else {
 			DB().RemoveEntity(entity, false)
@@ -5,6 +5,8 @@ go 1.18
 require (
 	github.com/robertkrimen/otto v0.0.0-20211024170158-b87d35c0b86f
 	gopkg.in/yaml.v3 v3.0.1
+	gorm.io/driver/sqlite v1.3.6
+	gorm.io/gorm v1.23.8
 )
 
 require (
@@ -12,6 +14,4 @@ require (
 	github.com/jinzhu/now v1.1.5 // indirect
 	github.com/mattn/go-sqlite3 v1.14.14 // indirect
 	gopkg.in/sourcemap.v1 v1.0.5 // indirect
-	gorm.io/driver/sqlite v1.3.6 // indirect
-	gorm.io/gorm v1.23.8 // indirect
 )
@@ -0,0 +1,280 @@
+/*  Star Wars Role-Playing Mud
+ *  Copyright (C) 2022 @{See Authors}
+ *
+ *  This program is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  This program is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *  GNU General Public License for more details.
+ *
+ *  You should have received a copy of the GNU General Public License
+ *  along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+package swr
+
+import (
+	"log"
+	"math"
+	"strings"
+)
+
+const (
+	HYPERSPACE_SPEED         = 10  // parsecs a ship covers every second in hyperspace.
+	HYPERSPACE_MIN_TIME      = 5   // seconds the shortest jump takes.
+	HYPERSPACE_SAFE_DISTANCE = 100 // how far a ship has to be from a planet's gravity well to jump.
+	HYPERDRIVE_DAMAGED       = 50  // hyperdrives below this health can give out in the middle of a jump.
+)
+
+// starsystem_match returns the star system called name, or the first one starting with it.
+func starsystem_match(name string) *StarSystemData {
+	if system := starsystem_find(name); system != nil {
+		return system
+	}
+	for _, s := range DB().starsystems {
+		if strings.HasPrefix(strings.ToLower(s.GetData().Name), strings.ToLower(name)) {
+			return s.GetData()
+		}
+	}
+	return nil
+}
+
+// starsystem_random_point returns somewhere in the system to come out of hyperspace, just
+// outside the gravity well of one of its planets.
+func starsystem_random_point(system *StarSystemData) []float32 {
+	center := []float32{0, 0}
+	orbits := starsystem_orbits(system)
+	if len(orbits) > 0 {
+		if o := orbits[rand_min_max(0, len(orbits)-1)]; len(o.Position) >= 2 {
+			center = o.Position
+		}
+	}
+	r := float64(rand_min_max(HYPERSPACE_SAFE_DISTANCE, HYPERSPACE_SAFE_DISTANCE*2))
+	a := random_float() * 2 * math.Pi
+	return []float32{center[0] + float32(r*math.Sin(a)), center[1] + float32(r*math.Cos(a))}
+}
+
+// starsystem_nearest returns the star system closest to a point in galactic space.
+func starsystem_nearest(pos []float32) *StarSystemData {
+	var ret *StarSystemData
+	best := float32(math.MaxFloat32)
+	for _, s := range DB().starsystems {
+		if d := distance_between_points(pos, s.GetData().Position); d < best {
+			ret, best = s.GetData(), d
+		}
+	}
+	return ret
+}
+
+// ship_gravity_well returns the name of the planet too close to the ship to jump, empty if it's clear.
+func ship_gravity_well(ship *ShipData) string {
+	system := starsystem_find(ship.CurrentSystem)
+	if system == nil {
+		return ""
+	}
+	for _, o := range starsystem_orbits(system) {
+		if len(o.Position) >= 2 && distance_between_points(ship.Position, o.Position) < HYPERSPACE_SAFE_DISTANCE {
+			return o.Name
+		}
+	}
+	return ""
+}
+
+// ship_prompt redraws the prompt of the players aboard, for the hyperspace countdown.
+func ship_prompt(ship *ShipData) {
+	for _, r := range ship.Rooms {
+		for _, e := range DB().GetEntitiesInRoom(r.Id, ship.Id) {
+			if e.IsPlayer() {
+				e.(*PlayerProfile).NeedPrompt = true
+				e.Prompt()
+			}
+		}
+	}
+}
+
+// ship_exit_hyperspace drops the ship out of hyperspace at pos in system.
+func ship_exit_hyperspace(ship *ShipData, system *StarSystemData, pos []float32) {
+	ship.InHyper = false
+	ship.HyperTimeUntil = 0
+	ship.HyperOrigin = nil
+	ship.HyperDestination = nil
+	ship.CurrentSystem = system.Name
+	ship.Position = pos
+	ship_echo(ship, sprintf("\r\n&YThe stars snap back into points as %s drops out of hyperspace in the %s system.&d\r\n", ship.Name, system.Name))
+	ship_prompt(ship)
+}
+
+// ship_hyper_state returns the jump the ship is in the middle of, for saving, or nil if it isn't in hyperspace.
+func ship_hyper_state(ship *ShipData) *ShipHyperState {
+	if !ship.InHyper || ship.HyperDestination == nil {
+		return nil
+	}
+	h := &ShipHyperState{
+		Destination: ship.HyperDestination.GetData().Name,
+		TimeUntil:   ship.HyperTimeUntil,
+		Duration:    ship.duration,
+		Arrival:     ship.arrival,
+		Misjump:     ship.misjump,
+	}
+	if ship.HyperOrigin != nil {
+		h.Origin = ship.HyperOrigin.GetData().Name
+	}
+	return h
+}
+
+// ship_load_hyper puts a loaded ship back into the jump it was saved in. If its destination
+// is gone the ship stays where it jumped from.
+func ship_load_hyper(ship *ShipData) {
+	h := ship.Hyper
+	ship.Hyper = nil
+	if h == nil {
+		return
+	}
+	to := starsystem_find(h.Destination)
+	if to == nil {
+		log.Printf("Error: ship %d was headed for %s in hyperspace, it doesn't exist! ship_load_hyper()", ship.Id, h.Destination)
+		return
+	}
+	ship.HyperDestination = to
+	if from := starsystem_find(h.Origin); from != nil {
+		ship.HyperOrigin = from
+	}
+	ship.HyperTimeUntil = h.TimeUntil
+	ship.duration = h.Duration
+	ship.arrival = h.Arrival
+	ship.misjump = h.Misjump
+	ship.InHyper = true
+	if len(ship.arrival) != 2 {
+		ship.arrival = starsystem_random_point(to)
+	}
+}
+
+// ship_hyperspace_tick counts down a ship's jump and brings it out of hyperspace when it
+// arrives. A worn out hyperdrive can give out along the way, dropping the ship in whatever
+// system is nearest.
+func ship_hyperspace_tick(ship *ShipData) {
+	if ship.HyperDestination == nil {
+		ship.InHyper = false
+		return
+	}
+	if ship.HyperTimeUntil > 0 {
+		ship.HyperTimeUntil--
+	}
+	if ship.HyperTimeUntil == 0 {
+		if ship.misjump {
+			ship_echo(ship, "\r\n&RThe nav computer got it wrong, this isn't where you meant to go!&d\r\n")
+			ship.misjump = false
+		}
+		ship_exit_hyperspace(ship, ship.HyperDestination.GetData(), ship.arrival)
+		return
+	}
+	health := int(ship.Modules[SHIP_MODULE_HYPERDRIVE])
+	if health < HYPERDRIVE_DAMAGED && roll_dice("1d1000") <= HYPERDRIVE_DAMAGED-health && ship.HyperOrigin != nil {
+		from := ship.HyperOrigin.GetData().Position
+		to := ship.HyperDestination.GetData().Position
+		done := float32(ship.duration-ship.HyperTimeUntil) / float32(umax(ship.duration, 1))
+		system := starsystem_nearest([]float32{from[0] + (to[0]-from[0])*done, from[1] + (to[1]-from[1])*done})
+		ship.misjump = false
+		ship_echo(ship, "\r\n&RThe hyperdrive shudders and gives out, dragging the ship back into realspace!&d\r\n")
+		ship_exit_hyperspace(ship, system, starsystem_random_point(system))
+		return
+	}
+	if ship.HyperTimeUntil%10 == 0 || ship.HyperTimeUntil <= 5 {
+		ship_prompt(ship)
+	}
+}
+
+func do_calculate(entity Entity, args ...string) {
+	s := entity_cockpit_ship(entity)
+	if s == nil {
+		return
+	}
+	if len(args) == 0 {
+		entity.Send("\r\n&YSyntax: calculate <system>&d\r\n")
+		return
+	}
+	if s.InHyper {
+		entity.Send("\r\n&RYou're already in hyperspace.&d\r\n")
+		return
+	}
+	system := starsystem_match(strings.Join(args, " "))
+	if system == nil {
+		entity.Send("\r\n&RThe nav computer has never heard of %s.&d\r\n", strings.Join(args, " "))
+		return
+	}
+	if s.Modules[SHIP_MODULE_HYPERDRIVE] == 0 {
+		entity.Send("\r\n&RThe ship has no working hyperdrive.&d\r\n")
+		return
+	}
+	origin := starsystem_find(s.CurrentSystem)
+	if !s.InSpace {
+		origin, _ = spaceport_find(s.LocationId)
+	}
+	if origin == nil {
+		entity.Send("\r\n&RThe nav computer can't work out where you are.&d\r\n")
+		return
+	}
+	if origin == system {
+		entity.Send("\r\n&RYou're already in the %s system.&d\r\n", system.Name)
+		return
+	}
+	_, seconds := hyperdrive_time_calculation(origin, system)
+	s.HyperDestination = system
+	s.misjump = false
+	// the further the jump, the more there is to get wrong.
+	if !entity_skill_check(entity, "hyperdrives", seconds/10) {
+		if roll_dice("1d2") == 1 {
+			s.HyperDestination = nil
+			entity.Send("\r\n&RThe nav computer chokes on your numbers, try again.&d\r\n")
+			return
+		}
+		s.misjump = true
+	}
+	entity.Send("\r\n&YThe nav computer plots a course to %s, %d seconds in hyperspace.&d\r\n", system.Name, seconds)
+}
+
+func do_hyperspace(entity Entity, args ...string) {
+	s := entity_piloting_ship(entity)
+	if s == nil {
+		return
+	}
+	if !s.InSpace {
+		entity.Send("\r\n&RYou need to launch first.&d\r\n")
+		return
+	}
+	if s.InHyper {
+		entity.Send("\r\n&RYou're already in hyperspace.&d\r\n")
+		return
+	}
+	if s.HyperDestination == nil {
+		entity.Send("\r\n&RYou need to calculate a course first.&d\r\n")
+		return
+	}
+	if s.Modules[SHIP_MODULE_HYPERDRIVE] == 0 {
+		entity.Send("\r\n&RThe ship has no working hyperdrive.&d\r\n")
+		return
+	}
+	if planet := ship_gravity_well(s); planet != "" {
+		entity.Send("\r\n&RYou're too close to %s, get clear of its gravity well first.&d\r\n", planet)
+		return
+	}
+	target := s.HyperDestination
+	if s.misjump {
+		others := make([]Starsystem, 0)
+		for _, system := range DB().starsystems {
+			if system.GetData() != target.GetData() && !strings.EqualFold(system.GetData().Name, s.CurrentSystem) {
+				others = append(others, system)
+			}
+		}
+		if len(others) > 0 {
+			target = others[rand_min_max(0, len(others)-1)]
+		}
+	}
+	s.JumpHyperspace(target, starsystem_random_point(target.GetData()))
+	ship_echo(s, sprintf("\r\n&YThe stars stretch into lines as %s jumps to hyperspace...&d\r\n", s.Name))
+	ship_prompt(s)
+}
@@ -17,9 +17,7 @@
  */
 package swr
 
-import (
-	"strings"
-)
+import "time"
 
 const (
 	ITEM_TYPE_GENERIC   = "generic"
@@ -75,20 +73,23 @@ func item_is_wearable_slot(str string) bool {
 }
 
 type ItemData struct {
-	Id         uint     `yaml:"id"`                      // instance id of the item
-	OId        uint     `yaml:"itemId,omitempty"`        // item type id.
-	Filename   string   `yaml:"-"`                       // filename for this item
-	Name       string   `yaml:"name"`                    // name of the item
-	Desc       string   `yaml:"desc"`                    // description of the item
-	Keywords   []string `yaml:"keywords,flow"`           // keywords for the item
-	Type       string   `yaml:"type"`                    // item type, a value of ITEM_TYPE_* const.
-	Value      int      `yaml:"value"`                   // how much is this item generally worth?
-	Weight     int      `yaml:"weight"`                  // how much does this item weigh?
-	AC         int      `yaml:"ac,omitempty"`            // If armored, what's the AC (common AC values are 1-8 for torso, 2-3 for hands/head/feet, 0-1 for waist)
-	WearLoc    *string  `yaml:"wearLoc,omitempty"`       // where is this item worn? nil means it's not wearable.
-	WeaponType *string  `yaml:"weaponType,omitempty"`    // weapon type from ITEM_WEAPON_TYPE_* const, nil means it's not a weapon.
-	Dmg        *string  `yaml:"dmgRoll,omitempty"`       // Damage roll represented by a D20 compatible string. Weapons do damage.
-	Items      []Item   `yaml:"contains,omitempty,flow"` // If item type is "container", then this is the list of stored items.
+	Id         uint        `yaml:"id"`                      // instance id of the item
+	OId        uint        `yaml:"itemId,omitempty"`        // item type id.
+	Filename   string      `yaml:"-"`                       // filename for this item
+	Name       string      `yaml:"name"`                    // name of the item
+	Desc       string      `yaml:"desc"`                    // description of the item
+	Keywords   []string    `yaml:"keywords,flow"`           // keywords for the item
+	Type       string      `yaml:"type"`                    // item type, a value of ITEM_TYPE_* const.
+	Value      int         `yaml:"value"`                   // how much is this item generally worth?
+	Weight     int         `yaml:"weight"`                  // how much does this item weigh?
+	AC         int         `yaml:"ac,omitempty"`            // If armored, what's the AC (common AC values are 1-8 for torso, 2-3 for hands/head/feet, 0-1 for waist)
+	WearLoc    *string     `yaml:"wearLoc,omitempty"`       // where is this item worn? nil means it's not wearable.
+	WeaponType *string     `yaml:"weaponType,omitempty"`    // weapon type from ITEM_WEAPON_TYPE_* const, nil means it's not a weapon.
+	Dmg        *string     `yaml:"dmgRoll,omitempty"`       // Damage roll represented by a D20 compatible string. Weapons do damage.
+	Items      []Item      `yaml:"contains,omitempty,flow"` // If item type is "container", then this is the list of stored items.
+	Decay      time.Time   `yaml:"decay,omitempty"`         // when a dropped item (or corpse) rots away, zero if it never does.
+	Extras     []ExtraDesc `yaml:"extras,omitempty"`        // details that can be looked at, an inscription say.
+	Protects   []string    `yaml:"protects,flow,omitempty"` // PROTECT_* the item gives its wearer, a breath mask say.
 }
 
 type Item interface {
@@ -154,6 +155,8 @@ func item_clone(item Item) Item {
 		WeaponType: i.WeaponType,
 		Dmg:        i.Dmg,
 		Items:      make([]Item, 0),
+		Extras:     i.Extras,
+		Protects:   i.Protects,
 	}
 	for idx := range i.Items {
 		con_item := i.Items[idx]
@@ -169,6 +172,25 @@ func item_clone(item Item) Item {
 	return c
 }
 
+// item_prototype_id is the vnum of the prototype the item was made from.
+func item_prototype_id(item Item) uint {
+	if item.GetData().OId > 0 {
+		return item.GetData().OId
+	}
+	return item.GetId()
+}
+
+// item_wear_slot is where the item goes when it's equipped, "" if it can't be.
+func item_wear_slot(item Item) string {
+	if item.IsWeapon() {
+		return "weapon"
+	}
+	if item.IsWearable() && item_is_wearable_slot(*item.GetData().WearLoc) {
+		return *item.GetData().WearLoc
+	}
+	return ""
+}
+
 func (i *ItemData) IsWeapon() bool {
 	return i.Type == ITEM_TYPE_1H_WEAPON || i.Type == ITEM_TYPE_2H_WEAPON
 }
@@ -187,15 +209,7 @@ func (i *ItemData) IsCorpse() bool {
 
 func (i *ItemData) FindItemInContainer(keyword string) Item {
 	if i.IsContainer() || i.IsCorpse() {
-		for id := range i.Items {
-			keys := i.Items[id].GetKeywords()
-			for k := range keys {
-				key := keys[k]
-				if strings.HasPrefix(key, keyword) {
-					return i.Items[id]
-				}
-			}
-		}
+		return target_item(i.Items, keyword)
 	}
 	return nil
 }
@@ -20,11 +20,12 @@ package swr
 import "strings"
 
 /*
-+----------------------+
-| @--@--@--@--@--@--@  |
-|    |     |           |
-|    |     |           |
-|    @--@--@           |
++-----------+
+|           |
+| @-@-@-@   |
+|   |   |   |
+|   @-@ @   |
++-----------+
 */
 
 const (
@@ -34,112 +35,195 @@ const (
 	MAP_EXIT_NWSE = "\\"
 	MAP_EXIT_SWNE = "/"
 )
-const MAPSIZE = 10
 
-func build_map(room *RoomData) string {
-	m := [MAPSIZE][MAPSIZE]string{}
-	for y := 0; y < MAPSIZE; y++ {
-		for x := 0; x < MAPSIZE; x++ {
-			m[x][y] = " "
-		}
+// MAPSIZE is how many rooms out from the middle the mini-map in look shows.
+const MAPSIZE = 2
+
+// map_sizes are the named sizes the map command takes, in rooms out from the middle.
+var map_sizes = map[string]int{
+	"small":  3,
+	"medium": 5,
+	"large":  8,
+}
+
+// map_deltas is which way each direction goes on the map, x is east, y is south and z is up.
+var map_deltas = map[string][3]int{
+	"north":     {0, -1, 0},
+	"northeast": {1, -1, 0},
+	"east":      {1, 0, 0},
+	"southeast": {1, 1, 0},
+	"south":     {0, 1, 0},
+	"southwest": {-1, 1, 0},
+	"west":      {-1, 0, 0},
+	"northwest": {-1, -1, 0},
+	"up":        {0, 0, 1},
+	"down":      {0, 0, -1},
+}
+
+// map_legend explains the map symbols.
+const map_legend = "&R@&d you  &Y@&d room  &P#&d player  &CS&d spaceport  &G$&d shop  &B^&d up  &Bv&d down  &Bx&d up and down\r\n" +
+	"&d-&d exit  &C-&d open door  &R-&d closed door"
+
+// map_node is a room placed on the map, where it is relative to the middle and how many steps away.
+type map_node struct {
+	room *RoomData
+	pos  [3]int
+	dist int
+}
+
+// room_coords returns the room's x, y, z coordinates, false if it doesn't have any.
+func room_coords(room *RoomData) ([3]int, bool) {
+	if len(room.Coords) != 3 {
+		return [3]int{}, false
 	}
-	m[MAPSIZE/2][MAPSIZE/2] = "&R@&W"
-	m = walk_rooms(m, room, MAPSIZE/2, MAPSIZE/2, 0)
-	for y := 0; y < MAPSIZE; y++ {
-		for x := 0; x < MAPSIZE; x++ {
-			if (x == 0 || x == MAPSIZE-1) && (y == MAPSIZE-1 || y == 0) {
-				m[x][y] = "&g+&W"
-			} else if (x == 0 || x == MAPSIZE-1) && (y != 0 && y < MAPSIZE-1) {
-				m[x][y] = "&g|&W"
-			} else if (y == MAPSIZE-1 || y == 0) && (x != 0 && x != MAPSIZE-1) {
-				m[x][y] = "&g-&W"
+	return [3]int{room.Coords[0], room.Coords[1], room.Coords[2]}, true
+}
+
+// map_layout places the rooms the entity could see within radius of room on a grid with room in the middle. Rooms with coordinates are
+// placed where their coordinates say, relative to the room they were reached from. Rooms without them are laid
+// out from the exit they were reached by. Each room is placed once, closest first, so cycles don't get walked
+// twice and the nearer room wins when two land on the same spot.
+func map_layout(entity Entity, room *RoomData, radius int) map[[3]int]*map_node {
+	ret := make(map[[3]int]*map_node)
+	if room == nil {
+		return ret
+	}
+	visited := map[uint]bool{room.Id: true}
+	queue := []*map_node{{room: room}}
+	ret[[3]int{}] = queue[0]
+	for len(queue) > 0 && len(visited) < PATH_MAX_ROOMS {
+		n := queue[0]
+		queue = queue[1:]
+		from, has_from := room_coords(n.room)
+		for _, exit := range n.room.GetExits() {
+			dir := exit.GetDirection()
+			delta, ok := map_deltas[dir]
+			if !ok || !room_exit_visible(entity, exit) {
+				continue
+			}
+			id := n.room.Exits[dir]
+			if visited[id] {
+				continue
+			}
+			to := DB().GetRoom(id, room.ship)
+			if to == nil {
+				continue
+			}
+			if c, ok := room_coords(to); ok && has_from {
+				delta = [3]int{c[0] - from[0], c[1] - from[1], c[2] - from[2]}
+			}
+			pos := [3]int{n.pos[0] + delta[0], n.pos[1] + delta[1], n.pos[2] + delta[2]}
+			// rooms off the edge of the map aren't walked, a floor up or down is so stairs can lead back.
+			if pos[0] < -radius || pos[0] > radius || pos[1] < -radius || pos[1] > radius || pos[2] < -1 || pos[2] > 1 {
+				continue
+			}
+			visited[id] = true
+			next := &map_node{room: to, pos: pos, dist: n.dist + 1}
+			if _, taken := ret[pos]; !taken {
+				ret[pos] = next
 			}
+			queue = append(queue, next)
 		}
 	}
-	buf := "&W"
-	for y := 0; y < MAPSIZE; y++ {
-		for x := 0; x < MAPSIZE; x++ {
-			buf += sprintf("&W%s&W", m[x][y])
+	return ret
+}
+
+// map_room_symbol is how a room is drawn on the map.
+func map_room_symbol(entity Entity, room *RoomData, middle bool) string {
+	if middle {
+		return "&R@&W"
+	}
+	for _, e := range room.GetEntities() {
+		if e != entity && e.IsPlayer() {
+			return "&P#&W"
 		}
-		buf += "&W\r\n&W"
 	}
-	return strings.TrimSpace(buf)
+	if room.HasFlag("spaceport") || room.HasFlag("shipyard") || room.HasFlag("hangar") {
+		return "&CS&W"
+	}
+	if room.HasFlag("shop") {
+		return "&G$&W"
+	}
+	up, down := room.HasExit("up"), room.HasExit("down")
+	if up && down {
+		return "&Bx&W"
+	}
+	if up {
+		return "&B^&W"
+	}
+	if down {
+		return "&Bv&W"
+	}
+	return "&Y" + MAP_ROOM + "&W"
 }
 
-func map_in_bounds(x int, y int) bool {
-	if x > 0 && x < MAPSIZE && y < MAPSIZE && y > 0 {
-		return true
+// map_exit_symbol is how the exit in direction is drawn, coloured if it's a door.
+func map_exit_symbol(room *RoomData, direction string) string {
+	s := MAP_EXIT_EW
+	switch direction {
+	case "north", "south":
+		s = MAP_EXIT_NS
+	case "northwest", "southeast":
+		s = MAP_EXIT_NWSE
+	case "northeast", "southwest":
+		s = MAP_EXIT_SWNE
+	}
+	exit := room.GetExit(direction)
+	if exit.IsClosed() {
+		return "&R" + s + "&W"
 	}
-	return false
+	if exit.IsDoor() {
+		return "&C" + s + "&W"
+	}
+	return "&d" + s + "&W"
 }
-func walk_rooms(m [MAPSIZE][MAPSIZE]string, room *RoomData, curX int, curY int, depth int) [MAPSIZE][MAPSIZE]string {
-	if map_in_bounds(curX, curY) && depth > 0 && !(curX == MAPSIZE/2 && curY == MAPSIZE/2) {
-		m[curX][curY] = "&Y@&W"
-	}
-	depth++
-	if depth > 6 {
-		return m
-	}
-	db := DB()
-	for d, e := range room.Exits {
-		switch d {
-		case "east":
-			x := curX + 1
-			y := curY
-			if map_in_bounds(x, y) {
-				m[x][y] = sprintf("&d%s&W", MAP_EXIT_EW)
-				m = walk_rooms(m, db.GetRoom(e, room.ship), x+1, y, depth)
-			}
-		case "west":
-			x := curX - 1
-			y := curY
-			if map_in_bounds(x, y) {
-				m[x][y] = sprintf("&d%s&W", MAP_EXIT_EW)
-				m = walk_rooms(m, db.GetRoom(e, room.ship), x-1, curY, depth)
-			}
-		case "north":
-			x := curX
-			y := curY - 1
-			if map_in_bounds(x, y) {
-				m[x][y] = sprintf("&d%s&W", MAP_EXIT_NS)
-				m = walk_rooms(m, db.GetRoom(e, room.ship), x, y-1, depth)
-			}
-		case "south":
-			x := curX
-			y := curY + 1
-			if map_in_bounds(x, y) {
-				m[x][y] = sprintf("&d%s&W", MAP_EXIT_NS)
-				m = walk_rooms(m, db.GetRoom(e, room.ship), x, y+1, depth)
-			}
-		case "northwest":
-			x := curX - 1
-			y := curY - 1
-			if map_in_bounds(x, y) {
-				m[x][y] = sprintf("&d%s&W", MAP_EXIT_NWSE)
-				m = walk_rooms(m, db.GetRoom(e, room.ship), x-1, y-1, depth)
-			}
-		case "southeast":
-			x := curX + 1
-			y := curY + 1
-			if map_in_bounds(x, y) {
-				m[x][y] = sprintf("&d%s&W", MAP_EXIT_NWSE)
-				m = walk_rooms(m, db.GetRoom(e, room.ship), x+1, y+1, depth)
+
+// map_render draws the rooms within radius of room, on the floor room is on, in a box. The entity is who's
+// looking, they aren't marked as another player. Hidden exits nobody has found aren't drawn.
+func map_render(entity Entity, room *RoomData, radius int) string {
+	size := radius*4 + 1
+	m := make([][]string, size+2)
+	for y := range m {
+		m[y] = make([]string, size+2)
+		for x := range m[y] {
+			m[y][x] = " "
+			edge_x := x == 0 || x == size+1
+			edge_y := y == 0 || y == size+1
+			if edge_x && edge_y {
+				m[y][x] = "&g+&W"
+			} else if edge_x {
+				m[y][x] = "&g|&W"
+			} else if edge_y {
+				m[y][x] = "&g-&W"
 			}
-		case "northeast":
-			x := curX + 1
-			y := curY - 1
-			if map_in_bounds(x, y) {
-				m[x][y] = sprintf("&d%s&W", MAP_EXIT_SWNE)
-				m = walk_rooms(m, db.GetRoom(e, room.ship), x+1, y-1, depth)
+		}
+	}
+	for pos, n := range map_layout(entity, room, radius) {
+		if pos[2] != 0 {
+			continue
+		}
+		x := pos[0]*2 + radius*2 + 1
+		y := pos[1]*2 + radius*2 + 1
+		m[y][x] = map_room_symbol(entity, n.room, n.room == room)
+		for _, exit := range n.room.GetExits() {
+			delta, ok := map_deltas[exit.GetDirection()]
+			if !ok || delta[2] != 0 || !room_exit_visible(entity, exit) {
+				continue
 			}
-		case "southwest":
-			x := curX - 1
-			y := curY + 1
-			if map_in_bounds(x, y) {
-				m[x][y] = sprintf("&d%s&W", MAP_EXIT_SWNE)
-				m = walk_rooms(m, db.GetRoom(e, room.ship), x-1, y+1, depth)
+			ex, ey := x+delta[0], y+delta[1]
+			if ex > 0 && ex <= size && ey > 0 && ey <= size && m[ey][ex] == " " {
+				m[ey][ex] = map_exit_symbol(n.room, exit.GetDirection())
 			}
 		}
 	}
-	return m
+	buf := "&W"
+	for y := range m {
+		buf += strings.Join(m[y], "") + "&W\r\n&W"
+	}
+	return strings.TrimSpace(buf)
+}
+
+// build_map is the mini-map shown next to a room's description.
+func build_map(room *RoomData) string {
+	return map_render(nil, room, MAPSIZE)
 }
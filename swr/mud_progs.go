@@ -20,7 +20,6 @@ package swr
 import (
 	"math/rand"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/robertkrimen/otto"
@@ -54,13 +53,17 @@ type Brain interface {
 	// OnSay event handler. Executes the brains "say" program.
 	OnSay(entity Entity, words string)
 
+	// OnSocial event handler. Executes the brains "social" program. When another entity uses a social on this one.
+	OnSocial(entity Entity, social string)
+
 	// Update is the main logic tree for AI and GenericBrain. It will figure out which action to take on the controlling entity.
 	Update()
 }
 
 type GenericBrain struct {
-	Entity Entity
-	vm     *otto.Otto
+	Entity  Entity
+	Hunting Entity // who the brain is chasing down, nil if nobody.
+	vm      *otto.Otto
 }
 
 // MakeGenericBrain creates a GenericBrain instance and wraps the entity in it. Effectively passing control to the brain.
@@ -98,21 +101,83 @@ func (b *GenericBrain) OnHeal(entity Entity) {
 func (b *GenericBrain) OnSay(entity Entity, words string) {
 	go mud_prog_exec(b.vm, "say", b.Entity, entity, words)
 }
+func (b *GenericBrain) OnSocial(entity Entity, social string) {
+	go mud_prog_exec(b.vm, "social", b.Entity, entity, social)
+}
 
 /* Update is called every server tick, it's the main logic tree for AI and {GenericBrain}
  */
 func (b *GenericBrain) Update() {
-	if b.Entity.GetCharData().State == ENTITY_STATE_NORMAL {
-		move := true
-		for _, f := range b.Entity.GetCharData().Flags {
-			if strings.ToLower(f) == "sentinel" {
-				move = false
-			}
+	ch := b.Entity.GetCharData()
+	if ch.State == ENTITY_STATE_NORMAL {
+		if b.Hunting != nil {
+			b.hunt()
+			return
 		}
+		move := !entity_has_flag(ch, "sentinel")
 		if roll_dice("1d30") == 30 && move {
 			// let's try to move...
 			b.Move()
 		}
+		if !move && ch.Home != 0 && ch.Room != ch.Home && roll_dice("1d5") == 5 {
+			b.GoHome()
+		}
+	}
+}
+
+// Hunt sets the brain on the trail of the entity, it will follow them and attack when it catches up.
+func (b *GenericBrain) Hunt(entity Entity) {
+	if entity == b.Entity {
+		return
+	}
+	b.Hunting = entity
+}
+
+// hunt takes a step toward who the brain is hunting, or attacks them if they're here.
+// The hunt is given up once they're dead, gone, or too far away.
+func (b *GenericBrain) hunt() {
+	ch := b.Entity.GetCharData()
+	target := b.Hunting
+	if DB().GetEntity(target) == nil {
+		b.Hunting = nil
+		return
+	}
+	tch := target.GetCharData()
+	if tch.State == ENTITY_STATE_DEAD || tch.State == ENTITY_STATE_UNCONSCIOUS || tch.Ship != ch.Ship {
+		b.Hunting = nil
+		return
+	}
+	room := DB().GetRoom(ch.Room, ch.Ship)
+	path := path_find(b.Entity, room, func(r *RoomData) bool {
+		return r.Id == tch.Room
+	}, 20)
+	if path == nil {
+		b.Hunting = nil
+		return
+	}
+	if len(path) > 0 {
+		if !path_step(b.Entity, path[0]) {
+			b.Hunting = nil
+		}
+		return
+	}
+	b.Hunting = nil
+	if tch.State == ENTITY_STATE_FIGHTING || entity_has_flag(ch, "nofight") || !room_can_fight(room, b.Entity, target) {
+		return
+	}
+	room.SendToOthers(target, sprintf("\r\n&R%s attacks %s!&d\r\n", ch.Name, tch.Name))
+	target.Send("\r\n&R%s has caught up with you and attacks!&d\r\n", ch.Name)
+	target.SetAttacker(b.Entity)
+	b.Entity.SetAttacker(target)
+}
+
+// GoHome takes a step back toward the room the brain's entity was spawned in.
+func (b *GenericBrain) GoHome() {
+	ch := b.Entity.GetCharData()
+	room := DB().GetRoom(ch.Room, ch.Ship)
+	path := path_find_room(b.Entity, room, ch.Home)
+	if len(path) > 0 {
+		path_step(b.Entity, path[0])
 	}
 }
 
@@ -121,19 +186,20 @@ func (b *GenericBrain) Update() {
 // same as a player.
 func (b *GenericBrain) Move() {
 	room := b.Entity.GetRoom()
-	total_exits := len(room.Exits)
-	exit := rand_min_max(0, total_exits)
-	count := 0
-	for i, e := range room.Exits {
-		if count == exit {
-			// only move if the room has an exit
-			// this prevents mobs getting stuck in "turbolift" rooms
-			to_room := DB().GetRoom(e, room.ship)
-			if len(to_room.Exits) > 0 {
-				do_direction(b.Entity, i)
-			}
+	exits := make([]string, 0)
+	for dir, id := range room.Exits {
+		// only move if the room has an exit
+		// this prevents mobs getting stuck in "turbolift" rooms
+		to_room := DB().GetRoom(id, room.ship)
+		if to_room == nil || len(to_room.Exits) == 0 || to_room.IsFlagged(ROOM_FLAG_NO_MOB) {
+			continue
 		}
-		count++
+		exits = append(exits, dir)
+	}
+	// sometimes the brain stays put.
+	exit := rand_min_max(0, len(exits))
+	if exit < len(exits) {
+		do_direction(b.Entity, exits[exit])
 	}
 }
 
@@ -162,7 +228,7 @@ The values are:
 
 	$me - The current entity {string}   (mob)
 	$n  - The other entity name {string}   (mob/player)
-	$s  - What was said on a 'say' event {string}   (player)
+	$s  - What was said on a 'say' event, or the social used on a 'social' event {string}   (player)
 */
 func mud_prog_bind(vm *otto.Otto, any ...interface{}) {
 	any_len := len(any)
@@ -185,6 +251,16 @@ func mud_prog_bind(vm *otto.Otto, any ...interface{}) {
 	}
 }
 
+// mud_prog_can checks the mob is in a position to use the command mapped to fn, the same as
+// do_command would if they'd typed it, since the bindings call the handlers directly.
+func mud_prog_can(entity Entity, fn string) bool {
+	com := command_find_func(fn)
+	if com == nil {
+		return true
+	}
+	return command_check_position(entity, com.Position)
+}
+
 // mud_prog_init initializes a new javascript virtual machine instance for the given entity.
 // It binds various mudprog functions useful for scripting mob interactions.
 func mud_prog_init(entity Entity) *otto.Otto {
@@ -198,19 +274,40 @@ func mud_prog_init(entity Entity) *otto.Otto {
 	}
 	// say("hello");
 	vm.Set("say", func(call otto.FunctionCall) otto.Value {
-		do_say(entity, call.Argument(0).String())
+		if mud_prog_can(entity, "do_say") {
+			do_say(entity, call.Argument(0).String())
+		}
 		return otto.Value{}
 	})
 	// shout("Stop!");
 	vm.Set("shout", func(call otto.FunctionCall) otto.Value {
-		do_shout(entity, call.Argument(0).String())
+		if mud_prog_can(entity, "do_shout") {
+			do_shout(entity, call.Argument(0).String())
+		}
 		return otto.Value{}
 	})
 	// emote("sits down");
 	vm.Set("emote", func(call otto.FunctionCall) otto.Value {
-		do_emote(entity, call.Argument(0).String())
+		if mud_prog_can(entity, "do_emote") {
+			do_emote(entity, call.Argument(0).String())
+		}
 		return otto.Value{}
 	})
+	// social("bow", $n);  - performs a social, the target is optional.
+	vm.Set("social", func(call otto.FunctionCall) otto.Value {
+		social := social_find(call.Argument(0).String())
+		if social == nil || !command_check_position(entity, POSITION_SITTING) {
+			v, _ := otto.ToValue(false)
+			return v
+		}
+		if len(call.ArgumentList) > 1 {
+			do_social(entity, social, call.Argument(1).String())
+		} else {
+			do_social(entity, social)
+		}
+		v, _ := otto.ToValue(true)
+		return v
+	})
 	// echo("straight to the terminal")
 	vm.Set("echo", func(call otto.FunctionCall) otto.Value {
 		entity.Send(call.Argument(0).String())
@@ -223,6 +320,53 @@ func mud_prog_init(entity Entity) *otto.Otto {
 		do_transfer(entity, entity_name, strconv.Itoa(int(room_value)))
 		return otto.Value{}
 	})
+	// hunt($n);  - chases down the entity named $n and attacks them when it catches up.
+	vm.Set("hunt", func(call otto.FunctionCall) otto.Value {
+		ch := entity.GetCharData()
+		target := target_entity(entity, DB().GetEntitiesInRoom(ch.Room, ch.Ship), call.Argument(0).String())
+		if target == nil {
+			target = DB().GetPlayerEntityByName(call.Argument(0).String())
+		}
+		if b, ok := ch.AI.(*GenericBrain); ok && target != nil {
+			b.Hunt(target)
+		}
+		return otto.Value{}
+	})
+	// weather();  - the weather where the entity is, "sandstorm" say, or "" if they're indoors.
+	vm.Set("weather", func(call otto.FunctionCall) otto.Value {
+		room := entity.GetRoom()
+		state := ""
+		if room_is_outdoors(room) {
+			state = room_get_weather(room).GetState().Name
+		}
+		v, _ := otto.ToValue(state)
+		return v
+	})
+	// is_day();  - true if the sun is up on the planet the entity is on.
+	vm.Set("is_day", func(call otto.FunctionCall) otto.Value {
+		w := room_get_weather(entity.GetRoom())
+		v, _ := otto.ToValue(w != nil && w.IsDay())
+		return v
+	})
+	// hour();  - the hour of the day where the entity is, the planet's if they're on one.
+	vm.Set("hour", func(call otto.FunctionCall) otto.Value {
+		hour := GetGameTime().Hour()
+		if w := room_get_weather(entity.GetRoom()); w != nil {
+			hour = w.GetHour()
+		}
+		v, _ := otto.ToValue(hour)
+		return v
+	})
+	// day();  - the day of the standard year, 1 to 368.
+	vm.Set("day", func(call otto.FunctionCall) otto.Value {
+		v, _ := otto.ToValue(GetGameTime().Day())
+		return v
+	})
+	// year();  - the standard year, years before the Battle of Yavin are negative.
+	vm.Set("year", func(call otto.FunctionCall) otto.Value {
+		v, _ := otto.ToValue(GetGameTime().Year())
+		return v
+	})
 	// delay(2);  - delay($n); where $n is an integer. delay will sleep the goroutine for $n seconds.
 	vm.Set("delay", func(call otto.FunctionCall) otto.Value {
 		t, _ := call.Argument(0).ToInteger()
@@ -262,23 +406,31 @@ func mud_prog_init(entity Entity) *otto.Otto {
 	})
 	// look();...  not sure how useful this is to the entity, maybe rework it so it makes the player ($n) perform a do_look...
 	vm.Set("look", func(call otto.FunctionCall) otto.Value {
-		do_look(entity)
+		if mud_prog_can(entity, "do_look") {
+			do_look(entity)
+		}
 		return otto.Value{}
 	})
 	// kill($n);  - makes the entity fight $n. Like scott pilgrim.
 	vm.Set("kill", func(call otto.FunctionCall) otto.Value {
 		target, _ := call.Argument(0).ToString()
-		do_fight(entity, target)
+		if mud_prog_can(entity, "do_kill") {
+			do_fight(entity, target)
+		}
 		return otto.Value{}
 	})
 	// stand();  -  makes the entity stand up.
 	vm.Set("stand", func(call otto.FunctionCall) otto.Value {
-		do_stand(entity)
+		if mud_prog_can(entity, "do_stand") {
+			do_stand(entity)
+		}
 		return otto.Value{}
 	})
 	// sit();  -  makes the entity stand up.
 	vm.Set("sit", func(call otto.FunctionCall) otto.Value {
-		do_sit(entity)
+		if mud_prog_can(entity, "do_sit") {
+			do_sit(entity)
+		}
 		return otto.Value{}
 	})
 	vm.Set("give", func(call otto.FunctionCall) otto.Value {
@@ -55,9 +55,13 @@ type TCPClient struct {
 	Editing bool
 	EditPtr *string
 	Queue   []string
+	Snooper Client // immortal client receiving a copy of everything sent to (and typed by) this client.
 }
 
 func (c *TCPClient) Send(str string) {
+	if c.Snooper != nil {
+		c.Snooper.Send(snoop_prefix(str))
+	}
 	str = Color().Colorize(str)
 	if c.Editing {
 		c.Queue = append(c.Queue, str)
@@ -102,7 +106,15 @@ func (c *TCPClient) Read() string {
 			if strings.HasSuffix(buf, "\n") {
 				buf = strings.TrimSuffix(buf, "\r\n")
 				buf = strings.TrimSuffix(buf, "\n")
-				return strings.TrimSpace(buf)
+				buf = strings.TrimSpace(buf)
+				if c.Snooper != nil && !c.Editing {
+					if command_input_hidden(buf) {
+						c.Snooper.Send("\r\n&P%&d &W[hidden]&d\r\n")
+					} else {
+						c.Snooper.Send(sprintf("\r\n&P%%&d &W%s&d\r\n", buf))
+					}
+				}
+				return buf
 			}
 		}
 	}
@@ -163,6 +175,17 @@ func (c *TCPClient) SendQueue() {
 func (c *TCPClient) ClearQueue() {
 	c.Queue = make([]string, 0)
 }
+func (c *TCPClient) SetSnooper(snooper Client) {
+	c.Snooper = snooper
+}
+func (c *TCPClient) GetSnooper() Client {
+	return c.Snooper
+}
+
+// snoop_prefix marks every line of snooped output with a % so it stands out from the snooper's own output.
+func snoop_prefix(str string) string {
+	return strings.ReplaceAll(str, "\n", "\n&P%&d ")
+}
 
 type Client interface {
 	IsClosed() bool
@@ -180,6 +203,8 @@ type Client interface {
 	GetIdle() int
 	SendQueue()
 	ClearQueue()
+	SetSnooper(snooper Client)
+	GetSnooper() Client
 }
 
 func ServerStart(addr string) {
@@ -0,0 +1,159 @@
+/*  Star Wars Role-Playing Mud
+ *  Copyright (C) 2022 @{See Authors}
+ *
+ *  This program is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  This program is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *  GNU General Public License for more details.
+ *
+ *  You should have received a copy of the GNU General Public License
+ *  along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+package swr
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PATH_MAX_ROOMS is as far as a path search will look before giving up.
+const PATH_MAX_ROOMS = 5000
+
+// path_find walks out from the room a ring at a time until it finds a room goal likes, and returns the
+// directions to get there. Exits the entity can't see or can't unlock are skipped, closed doors are fine,
+// they can be opened. Paths never leave the ship (or planet) the room is on. Rooms further than depth steps
+// away aren't searched, 0 searches as far as PATH_MAX_ROOMS. Returns nil if there's no way there, and an
+// empty path if the room is already the goal.
+func path_find(entity Entity, from *RoomData, goal func(*RoomData) bool, depth int) []string {
+	if from == nil {
+		return nil
+	}
+	if goal(from) {
+		return []string{}
+	}
+	type step struct {
+		room *RoomData
+		prev int
+		dir  string
+	}
+	visited := map[uint]bool{from.Id: true}
+	steps := []step{{room: from, prev: -1}}
+	ring_start := 0
+	for d := 1; depth == 0 || d <= depth; d++ {
+		ring_end := len(steps)
+		if ring_start == ring_end {
+			return nil
+		}
+		for i := ring_start; i < ring_end; i++ {
+			room := steps[i].room
+			for _, exit := range room.GetExits() {
+				if !path_can_pass(entity, room, exit) {
+					continue
+				}
+				id := room.Exits[exit.GetDirection()]
+				if visited[id] {
+					continue
+				}
+				visited[id] = true
+				to := DB().GetRoom(id, from.ship)
+				if to == nil {
+					continue
+				}
+				steps = append(steps, step{room: to, prev: i, dir: exit.GetDirection()})
+				if goal(to) {
+					ret := make([]string, 0, d)
+					for s := len(steps) - 1; s > 0; s = steps[s].prev {
+						ret = append([]string{steps[s].dir}, ret...)
+					}
+					return ret
+				}
+				if len(steps) > PATH_MAX_ROOMS {
+					return nil
+				}
+			}
+		}
+		ring_start = ring_end
+	}
+	return nil
+}
+
+// path_find_room returns the directions from the room to the room with vnum id.
+func path_find_room(entity Entity, from *RoomData, id uint) []string {
+	return path_find(entity, from, func(r *RoomData) bool {
+		return r.Id == id
+	}, 0)
+}
+
+// path_can_pass is true if the entity could go through the exit, a nil entity can't open locks, nobody goes where they lack the gear for
+// and mobs stay out of no_mob rooms.
+func path_can_pass(entity Entity, room *RoomData, exit Exit) bool {
+	if !room_exit_visible(entity, exit) {
+		return false
+	}
+	if exit.IsLocked() && (entity == nil || exit.GetKeyId() == 0 || entity.GetCharData().GetItem(exit.GetKeyId()) == nil) {
+		return false
+	}
+	if to, ok := exit.GetTarget().(*RoomData); ok && to != nil && entity != nil {
+		if !entity_can_enter(entity, to) || (!entity.IsPlayer() && to.IsFlagged(ROOM_FLAG_NO_MOB)) {
+			return false
+		}
+	}
+	return true
+}
+
+// path_step moves the entity one step along a path, opening the door on the way if it needs to.
+// Returns true if the entity made it through.
+func path_step(entity Entity, direction string) bool {
+	room := DB().GetRoom(entity.RoomId(), entity.ShipId())
+	if room == nil {
+		return false
+	}
+	exit := room.GetExit(direction)
+	if exit == nil {
+		return false
+	}
+	if exit.IsLocked() {
+		room.UnlockDoor(entity, direction, true)
+	}
+	if exit.IsClosed() {
+		room.OpenDoor(entity, direction, false)
+	}
+	was := entity.RoomId()
+	do_direction(entity, direction)
+	return entity.RoomId() != was
+}
+
+// path_string shortens a path for printing, north north east is 2n e.
+func path_string(path []string) string {
+	ret := make([]string, 0)
+	for i := 0; i < len(path); {
+		n := 1
+		for i+n < len(path) && path[i+n] == path[i] {
+			n++
+		}
+		dir := direction_short(path[i])
+		if n > 1 {
+			dir = strconv.Itoa(n) + dir
+		}
+		ret = append(ret, dir)
+		i += n
+	}
+	return strings.Join(ret, " ")
+}
+
+// direction_short returns the short form of a direction, northeast is ne.
+func direction_short(direction string) string {
+	switch direction {
+	case "north", "south", "east", "west", "up", "down":
+		return direction[:1]
+	case "northeast", "northwest", "southeast", "southwest":
+		return direction[:1] + direction[5:6]
+	}
+	return direction
+}
@@ -0,0 +1,461 @@
+/*  Star Wars Role-Playing Mud
+ *  Copyright (C) 2022 @{See Authors}
+ *
+ *  This program is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  This program is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *  GNU General Public License for more details.
+ *
+ *  You should have received a copy of the GNU General Public License
+ *  along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+package swr
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	SHIP_TICK            = 1  // seconds between moving the ships in space.
+	SHIP_LAND_DISTANCE   = 50 // how close a ship has to be to an orbital to land on it.
+	SHIP_LAND_DIFFICULTY = 10 // landing is harder than taking off.
+)
+
+// ship_is_cockpit is true if roomId is one of the ship's cockpits.
+func ship_is_cockpit(ship *ShipData, roomId uint) bool {
+	if ship.Cockpit == roomId {
+		return true
+	}
+	if r, ok := ship.Rooms[roomId]; ok {
+		return r.HasFlag(SHIP_ROOM_FLAGS_COCKPIT)
+	}
+	return false
+}
+
+// ship_get_pilot returns who is flying the ship, nil if nobody is at the controls. Pilots
+// that have walked off, quit or passed out let go of the controls.
+func ship_get_pilot(ship *ShipData) Entity {
+	p := ship.Pilot
+	if p == nil {
+		return nil
+	}
+	ch := p.GetCharData()
+	if ch.State != ENTITY_STATE_PILOTING || ch.Ship != ship.Id || !ship_is_cockpit(ship, ch.Room) || DB().GetEntity(p) == nil {
+		ship.Pilot = nil
+		return nil
+	}
+	return p
+}
+
+// entity_stop_piloting lets go of the controls of whatever ship entity is flying.
+func entity_stop_piloting(entity Entity) {
+	ch := entity.GetCharData()
+	if ch.State != ENTITY_STATE_PILOTING {
+		return
+	}
+	ch.State = ENTITY_STATE_NORMAL
+	if ship := entity.GetShip(); ship != nil && ship.GetData().Pilot == entity {
+		ship.GetData().Pilot = nil
+	}
+	entity.Send("\r\n&YYou let go of the controls.&d\r\n")
+}
+
+// entity_cockpit_ship returns the ship entity is in the cockpit of, telling them why not
+// and returning nil if they aren't in one.
+func entity_cockpit_ship(entity Entity) *ShipData {
+	ship := entity.GetShip()
+	if ship == nil {
+		entity.Send("\r\n&RYou need to be aboard a ship.&d\r\n")
+		return nil
+	}
+	s := ship.GetData()
+	if !ship_is_cockpit(s, entity.RoomId()) {
+		entity.Send("\r\n&RYou need to be in the cockpit.&d\r\n")
+		return nil
+	}
+	return s
+}
+
+// entity_piloting_ship is entity_cockpit_ship for the one at the controls.
+func entity_piloting_ship(entity Entity) *ShipData {
+	s := entity_cockpit_ship(entity)
+	if s == nil {
+		return nil
+	}
+	if ship_get_pilot(s) != entity {
+		entity.Send("\r\n&RYou aren't flying the ship, pilot it first.&d\r\n")
+		return nil
+	}
+	return s
+}
+
+// ship_echo sends msg to everyone aboard the ship.
+func ship_echo(ship *ShipData, msg string) {
+	for _, r := range ship.Rooms {
+		for _, e := range DB().GetEntitiesInRoom(r.Id, ship.Id) {
+			e.Send(msg)
+		}
+	}
+}
+
+// ship_max_speed is how fast the ship can go with the engines it has left.
+func ship_max_speed(ship *ShipData) int {
+	engine, ok := ship.Modules[SHIP_MODULE_ENGINE]
+	if !ok {
+		engine = 100
+	}
+	return int(ship.MaxSpeed) * int(engine) / 100
+}
+
+// starsystem_find returns the star system called name, nil if there isn't one.
+func starsystem_find(name string) *StarSystemData {
+	for _, s := range DB().starsystems {
+		if strings.EqualFold(s.GetData().Name, name) {
+			return s.GetData()
+		}
+	}
+	return nil
+}
+
+// starsystem_orbits returns the system's orbitals in order.
+func starsystem_orbits(system *StarSystemData) []OribitalObject {
+	keys := make([]int, 0, len(system.Orbits))
+	for k := range system.Orbits {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	ret := make([]OribitalObject, 0, len(keys))
+	for _, k := range keys {
+		ret = append(ret, system.Orbits[k])
+	}
+	return ret
+}
+
+// spaceport_find returns the system and orbital that list roomId as a spaceport. Several
+// orbitals can share a spaceport, the one the room's area is named after wins.
+func spaceport_find(roomId uint) (*StarSystemData, *OribitalObject) {
+	var system *StarSystemData
+	var orbit *OribitalObject
+	area := ""
+	if room := DB().GetRoom(roomId, 0); room != nil && room.Area != nil {
+		area = weather_key(room.Area.Name)
+	}
+	for _, s := range DB().starsystems {
+		for _, o := range starsystem_orbits(s.GetData()) {
+			for _, port := range o.Spaceports {
+				if uint(port) != roomId {
+					continue
+				}
+				if orbit == nil || weather_key(o.Name) == area {
+					system, orbit = s.GetData(), &OribitalObject{}
+					*orbit = o
+				}
+			}
+		}
+	}
+	return system, orbit
+}
+
+// ship_bearing is the heading from one point to another, 0 is north and 90 east.
+func ship_bearing(from []float32, to []float32) int {
+	deg := math.Atan2(float64(to[0]-from[0]), float64(to[1]-from[1])) * 180 / math.Pi
+	return int(floor_mod(int64(math.Round(deg)), 360))
+}
+
+// ship_landing_pads returns the landable spaceports in range of the ship, and the orbital each one is on.
+func ship_landing_pads(ship *ShipData) ([]*RoomData, []string) {
+	rooms := make([]*RoomData, 0)
+	orbits := make([]string, 0)
+	system := starsystem_find(ship.CurrentSystem)
+	if system == nil {
+		return rooms, orbits
+	}
+	for _, o := range starsystem_orbits(system) {
+		if len(o.Position) < 2 || distance_between_points(ship.Position, o.Position) > SHIP_LAND_DISTANCE {
+			continue
+		}
+		for _, port := range o.Spaceports {
+			room := DB().GetRoom(uint(port), 0)
+			if room != nil && room_is_landable(room) {
+				rooms = append(rooms, room)
+				orbits = append(orbits, o.Name)
+			}
+		}
+	}
+	return rooms, orbits
+}
+
+func do_pilot(entity Entity, args ...string) {
+	s := entity_cockpit_ship(entity)
+	if s == nil {
+		return
+	}
+	ch := entity.GetCharData()
+	pilot := ship_get_pilot(s)
+	if pilot == entity {
+		entity_stop_piloting(entity)
+		entity.GetRoom().SendToOthers(entity, sprintf("\r\n%s gets up from the pilot's seat.\r\n", ch.Name))
+		return
+	}
+	if pilot != nil {
+		entity.Send("\r\n&R%s is already flying the ship.&d\r\n", pilot.GetCharData().Name)
+		return
+	}
+	switch ch.State {
+	case "", ENTITY_STATE_NORMAL, ENTITY_STATE_PILOTING:
+	default:
+		entity.Send("\r\n&RYou're in no shape to fly.&d\r\n")
+		return
+	}
+	s.Pilot = entity
+	ch.State = ENTITY_STATE_PILOTING
+	entity.Send("\r\n&YYou take the controls of %s.&d\r\n", s.Name)
+	entity.GetRoom().SendToOthers(entity, sprintf("\r\n%s takes the pilot's seat.\r\n", ch.Name))
+}
+
+func do_launch(entity Entity, args ...string) {
+	s := entity_piloting_ship(entity)
+	if s == nil {
+		return
+	}
+	if s.InSpace {
+		entity.Send("\r\n&RYou're already in space.&d\r\n")
+		return
+	}
+	system, orbit := spaceport_find(s.LocationId)
+	if system == nil || len(orbit.Position) < 2 {
+		entity.Send("\r\n&RFlight control won't clear you for launch from here.&d\r\n")
+		return
+	}
+	if !entity_skill_check(entity, "piloting", 0) {
+		entity.Send("\r\n&RYou fumble the launch sequence and the engines sputter out.&d\r\n")
+		ship_echo(s, "\r\n&YThe ship shudders and settles back onto its landing struts.&d\r\n")
+		return
+	}
+	room := DB().GetRoom(s.LocationId, 0)
+	s.InSpace = true
+	s.CurrentSystem = system.Name
+	s.Position = []float32{orbit.Position[0], orbit.Position[1]}
+	s.Heading = 0
+	s.Speed = 0
+	ship_echo(s, sprintf("\r\n&YThe engines roar as %s lifts off and climbs into orbit around %s.&d\r\n", s.Name, orbit.Name))
+	if room != nil {
+		room.SendToRoom(sprintf("\r\n%s launches into the sky.\r\n", s.Name))
+	}
+}
+
+func do_land(entity Entity, args ...string) {
+	s := entity_piloting_ship(entity)
+	if s == nil {
+		return
+	}
+	if !s.InSpace {
+		entity.Send("\r\n&RYou're already on the ground.&d\r\n")
+		return
+	}
+	if s.InHyper {
+		entity.Send("\r\n&RNot while you're in hyperspace!&d\r\n")
+		return
+	}
+	rooms, orbits := ship_landing_pads(s)
+	if len(args) == 0 {
+		if len(rooms) == 0 {
+			entity.Send("\r\n&RThere's nowhere to land in range.&d\r\n")
+			return
+		}
+		entity.Send("\r\n&YSyntax: land <spaceport>&d\r\n")
+		entity.Send("&cSpaceports in range:&d\r\n")
+		for i, r := range rooms {
+			entity.Send("  &W%-20s &Y%s&d\r\n", orbits[i], r.Name)
+		}
+		return
+	}
+	name := strings.ToLower(strings.Join(args, " "))
+	var room *RoomData
+	for i, r := range rooms {
+		if strings.HasPrefix(strings.ToLower(r.Name), name) || strings.HasPrefix(strings.ToLower(orbits[i]), name) || name == strconv.Itoa(int(r.Id)) {
+			room = r
+			break
+		}
+	}
+	if room == nil {
+		entity.Send("\r\n&RYou can't land at %s from here.&d\r\n", strings.Join(args, " "))
+		return
+	}
+	if !entity_skill_check(entity, "piloting", SHIP_LAND_DIFFICULTY) {
+		entity.Send("\r\n&RYou come in too steep and have to pull up and go around.&d\r\n")
+		return
+	}
+	s.InSpace = false
+	s.LocationId = room.Id
+	s.Speed = 0
+	ship_echo(s, sprintf("\r\n&Y%s touches down at %s.&d\r\n", s.Name, room.Name))
+	room.SendToRoom(sprintf("\r\n%s comes in to land.\r\n", s.Name))
+}
+
+func do_course(entity Entity, args ...string) {
+	s := entity_piloting_ship(entity)
+	if s == nil {
+		return
+	}
+	if !s.InSpace {
+		entity.Send("\r\n&RYou need to launch first.&d\r\n")
+		return
+	}
+	if s.InHyper {
+		entity.Send("\r\n&RNot while you're in hyperspace!&d\r\n")
+		return
+	}
+	if len(args) == 0 {
+		entity.Send("\r\n&YSyntax: course <heading|planet>&d\r\n")
+		entity.Send("&cYou're heading &W%d&c.&d\r\n", int(s.Heading))
+		return
+	}
+	heading, err := strconv.Atoi(args[0])
+	if err != nil {
+		name := strings.ToLower(strings.Join(args, " "))
+		found := false
+		if system := starsystem_find(s.CurrentSystem); system != nil {
+			for _, o := range starsystem_orbits(system) {
+				if strings.HasPrefix(strings.ToLower(o.Name), name) && len(o.Position) >= 2 {
+					heading = ship_bearing(s.Position, o.Position)
+					found = true
+					break
+				}
+			}
+		}
+		if !found {
+			entity.Send("\r\n&RThere's no %s in this system.&d\r\n", strings.Join(args, " "))
+			return
+		}
+	}
+	s.Heading = float32(floor_mod(int64(heading), 360))
+	entity.Send("\r\n&YCourse set, heading %d.&d\r\n", int(s.Heading))
+}
+
+func do_speed(entity Entity, args ...string) {
+	s := entity_piloting_ship(entity)
+	if s == nil {
+		return
+	}
+	if !s.InSpace {
+		entity.Send("\r\n&RYou need to launch first.&d\r\n")
+		return
+	}
+	max := ship_max_speed(s)
+	speed := -1
+	if len(args) > 0 {
+		if n, err := strconv.Atoi(args[0]); err == nil {
+			speed = n
+		}
+	}
+	if speed < 0 {
+		entity.Send("\r\n&YSyntax: speed <0-%d>&d\r\n", max)
+		entity.Send("&cYou're going &W%d&c.&d\r\n", int(s.Speed))
+		return
+	}
+	if speed > max {
+		entity.Send("\r\n&YThe engines won't give any more than %d.&d\r\n", max)
+		speed = max
+	}
+	s.Speed = float32(speed)
+	entity.Send("\r\n&YThe engines hum as you bring the ship to speed %d.&d\r\n", speed)
+}
+
+func do_ship_status(entity Entity, args ...string) {
+	s := entity_cockpit_ship(entity)
+	if s == nil {
+		return
+	}
+	pilot := "Nobody"
+	if p := ship_get_pilot(s); p != nil {
+		pilot = p.GetCharData().Name
+	}
+	entity.Send("\r\n%s\r\n", MakeTitle(s.Name, ANSI_TITLE_STYLE_NORMAL, ANSI_TITLE_ALIGNMENT_CENTER))
+	entity.Send("&c    Type: &W%-24s &cPilot: &W%s&d\r\n", s.Type, pilot)
+	if len(s.Hp) == 2 && len(s.Sp) == 2 {
+		entity.Send("&c    Hull: &W%-24s &cShields: &W%d/%d&d\r\n", sprintf("%d/%d", s.Hp[0], s.Hp[1]), s.Sp[0], s.Sp[1])
+	}
+	if !s.InSpace {
+		location := "Somewhere"
+		if room := DB().GetRoom(s.LocationId, 0); room != nil {
+			location = room.Name
+		}
+		entity.Send("&c  Landed: &W%s&d\r\n", location)
+	} else if s.InHyper {
+		entity.Send("&cHyperspace: &W%d&c seconds to go.&d\r\n", s.HyperTimeUntil)
+	} else {
+		entity.Send("&c  System: &W%-24s &cPosition: &W%.1f, %.1f&d\r\n", s.CurrentSystem, s.Position[0], s.Position[1])
+		entity.Send("&c Heading: &W%-24d &cSpeed: &W%d/%d&d\r\n", int(s.Heading), int(s.Speed), ship_max_speed(s))
+		if s.HyperDestination != nil {
+			entity.Send("&c  Course: &Wplotted for %s&d\r\n", s.HyperDestination.GetData().Name)
+		}
+	}
+	keys := make([]string, 0, len(s.Modules))
+	for k := range s.Modules {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	modules := make([]string, 0, len(keys))
+	for _, k := range keys {
+		modules = append(modules, sprintf("&W%s &Y%d%%&c", k, s.Modules[k]))
+	}
+	entity.Send("&c Modules: %s&d\r\n", strings.Join(modules, ", "))
+	if s.InSpace && !s.InHyper {
+		if system := starsystem_find(s.CurrentSystem); system != nil {
+			entity.Send("&c  Nearby:&d\r\n")
+			for _, o := range starsystem_orbits(system) {
+				if len(o.Position) < 2 {
+					continue
+				}
+				d := distance_between_points(s.Position, o.Position)
+				port := ""
+				if d <= SHIP_LAND_DISTANCE && len(o.Spaceports) > 0 {
+					port = " &G(in landing range)"
+				}
+				entity.Send("&c    &W%-20s &c%8.1f away, heading &W%d%s&d\r\n", o.Name, d, ship_bearing(s.Position, o.Position), port)
+			}
+		}
+	}
+	entity.Send("\r\n")
+}
+
+// processShips flies the ships in space along their heading and counts down the ones in
+// hyperspace. Pilots are told when they come into landing range of somewhere.
+func processShips() {
+	for _, ship := range DB().GetShipsInSpace() {
+		s := ship.GetData()
+		if s.InHyper {
+			ship_hyperspace_tick(s)
+			continue
+		}
+		if s.Speed <= 0 || len(s.Position) < 2 {
+			continue
+		}
+		from := []float32{s.Position[0], s.Position[1]}
+		rad := float64(s.Heading) * math.Pi / 180
+		s.Position[0] += s.Speed * float32(math.Sin(rad))
+		s.Position[1] += s.Speed * float32(math.Cos(rad))
+		system := starsystem_find(s.CurrentSystem)
+		if system == nil {
+			continue
+		}
+		for _, o := range starsystem_orbits(system) {
+			if len(o.Position) < 2 || len(o.Spaceports) == 0 {
+				continue
+			}
+			if distance_between_points(from, o.Position) > SHIP_LAND_DISTANCE && distance_between_points(s.Position, o.Position) <= SHIP_LAND_DISTANCE {
+				ship_echo(s, sprintf("\r\n&C%s is in landing range.&d\r\n", o.Name))
+			}
+		}
+	}
+}
@@ -19,34 +19,82 @@ package swr
 
 import (
 	"fmt"
-	"log"
+	"reflect"
+	"sort"
 	"strings"
 )
 
+// ResetItem is an item a reset gives to a mob or puts in a container, with whatever it holds.
+type ResetItem struct {
+	Item     uint        `yaml:"item"`
+	Chance   uint        `yaml:"chance,omitempty"`   // percent chance of it being there, 0 is always.
+	Contents []ResetItem `yaml:"contents,omitempty"` // items put inside it, if it's a container.
+}
+
 type MobSpawn struct {
-	Mob    uint   `yaml:"mob"`
-	Room   uint   `yaml:"room"`
-	entity Entity `yaml:"-"`
+	Mob      uint        `yaml:"mob"`
+	Room     uint        `yaml:"room"`
+	Ship     uint        `yaml:"ship,omitempty"`      // spawn in a room of this ship instead of the area.
+	Chance   uint        `yaml:"chance,omitempty"`    // percent chance of spawning at each reset, 0 is always.
+	MaxWorld uint        `yaml:"max_world,omitempty"` // don't spawn while this many of the mob are in the game, 0 is no limit.
+	MaxRoom  uint        `yaml:"max_room,omitempty"`  // don't spawn while this many of the mob are in the room, 0 is no limit.
+	Equip    []ResetItem `yaml:"equip,omitempty"`     // items the mob spawns wearing.
+	Give     []ResetItem `yaml:"give,omitempty"`      // items the mob spawns carrying.
+	entity   Entity      `yaml:"-"`
 }
 
 type ItemSpawn struct {
-	Item uint `yaml:"item"`
-	Room uint `yaml:"room"`
+	Item     uint        `yaml:"item"`
+	Room     uint        `yaml:"room"`
+	Ship     uint        `yaml:"ship,omitempty"`      // spawn in a room of this ship instead of the area.
+	Chance   uint        `yaml:"chance,omitempty"`    // percent chance of spawning at each reset, 0 is always.
+	MaxWorld uint        `yaml:"max_world,omitempty"` // don't spawn while this many of the item are in the game, 0 is no limit.
+	MaxRoom  uint        `yaml:"max_room,omitempty"`  // don't spawn while this many of the item are in the room, 0 is 1.
+	Contents []ResetItem `yaml:"contents,omitempty"`  // items put inside it, topped up at each reset.
+}
+
+const (
+	DOOR_OPEN   = "open"
+	DOOR_CLOSED = "closed"
+	DOOR_LOCKED = "locked"
+)
+
+// DoorReset sets a door open, closed or locked at each reset, on both sides.
+type DoorReset struct {
+	Room      uint   `yaml:"room"`
+	Ship      uint   `yaml:"ship,omitempty"`
+	Direction string `yaml:"dir"`
+	State     string `yaml:"state"` // DOOR_OPEN, DOOR_CLOSED or DOOR_LOCKED.
 }
 
 type AreaData struct {
-	Name     string      `yaml:"name"`
-	Author   string      `yaml:"author,omitempty"`
-	Levels   []uint16    `yaml:"levels,flow"`
-	Reset    uint        `yaml:"reset"`
-	ResetMsg string      `yaml:"reset_msg"`
-	Rooms    []RoomData  `yaml:"rooms"`
-	Mobs     []MobSpawn  `yaml:"mobs,omitempty"`
-	Items    []ItemSpawn `yaml:"items,omitempty"`
+	Name          string      `yaml:"name"`
+	Author        string      `yaml:"author,omitempty"`
+	Levels        []uint16    `yaml:"levels,flow"`
+	ResetInterval uint        `yaml:"reset"` // seconds between resets.
+	ResetMsg      string      `yaml:"reset_msg"`
+	RoomVnums     []uint      `yaml:"room_vnums,flow,omitempty"` // [min, max] room vnums the area owns.
+	MobVnums      []uint      `yaml:"mob_vnums,flow,omitempty"`  // [min, max] mob vnums the area owns.
+	ItemVnums     []uint      `yaml:"item_vnums,flow,omitempty"` // [min, max] item vnums the area owns.
+	Sector        string      `yaml:"sector,omitempty"`          // SECTOR_* the rooms default to, SECTOR_INSIDE if empty.
+	Flags         []string    `yaml:"flags,flow,omitempty"`      // ROOM_FLAG_* every room in the area has.
+	Rooms         []RoomData  `yaml:"rooms"`                     // room prototypes, the live rooms are in [GameDatabase.rooms].
+	Mobs          []MobSpawn  `yaml:"mobs,omitempty"`
+	Items         []ItemSpawn `yaml:"items,omitempty"`
+	Doors         []DoorReset `yaml:"doors,omitempty"`
 }
+
+const (
+	VNUM_ROOM = "room"
+	VNUM_MOB  = "mob"
+	VNUM_ITEM = "item"
+)
+
+var vnum_kinds = []string{VNUM_ROOM, VNUM_MOB, VNUM_ITEM}
+
 type Area interface {
 	Delete() error
-	DeleteRoom(index uint)
+	DeleteRoom(id uint)
 	DeleteMobSpawn(index uint)
 	DeleteObjectSpawn(index uint)
 	GetName() string
@@ -54,6 +102,7 @@ type Area interface {
 	GetLevels() []uint16
 	GetReset() uint
 	GetResetMsg() string
+	GetVnums(kind string) []uint
 	GetRooms() []Room
 	GetMobSpawns() []MobSpawn
 	GetObjectSpawns() []ItemSpawn
@@ -64,6 +113,7 @@ type Area interface {
 	SetAuthor(author string)
 	SetReset(seconds uint)
 	SetResetMsg(message string)
+	SetVnums(kind string, min uint, max uint)
 	SetRooms(rooms []Room)
 	SetRoom(id uint, room Room)
 	SetMobSpawns(mob_spawns []MobSpawn)
@@ -72,6 +122,30 @@ type Area interface {
 	SetObjectSpawn(index uint, obj_id uint, room_id uint)
 }
 
+// Room flags, a room can have any of these in its [RoomData.Flags], or its area can have them for every room in it.
+const (
+	ROOM_FLAG_INDOORS   = "indoors"   // under a roof, the weather and the time of day don't reach it.
+	ROOM_FLAG_SAFE      = "safe"      // nobody can fight here.
+	ROOM_FLAG_NO_PK     = "no_pk"     // players can't fight each other here, mobs are fair game.
+	ROOM_FLAG_NO_MOB    = "no_mob"    // mobs don't wander or hunt in here.
+	ROOM_FLAG_NO_RECALL = "no_recall" // mob progs can't transfer anyone out.
+	ROOM_FLAG_NO_SHIP   = "no_ship"   // ships can't land here.
+	ROOM_FLAG_PRIVATE   = "private"   // room for two players and no more.
+	ROOM_FLAG_SPACEPORT = "spaceport"
+	ROOM_FLAG_SHIPYARD  = "shipyard"
+	ROOM_FLAG_HANGAR    = "hangar"
+	ROOM_FLAG_SHOP      = "shop"
+)
+
+// ROOM_PRIVATE_MAX is how many players fit in a private room.
+const ROOM_PRIVATE_MAX = 2
+
+var room_flag_list = []string{
+	ROOM_FLAG_INDOORS, ROOM_FLAG_SAFE, ROOM_FLAG_NO_PK, ROOM_FLAG_NO_MOB, ROOM_FLAG_NO_RECALL, ROOM_FLAG_NO_SHIP, ROOM_FLAG_PRIVATE,
+	ROOM_FLAG_SPACEPORT, ROOM_FLAG_SHIPYARD, ROOM_FLAG_HANGAR, ROOM_FLAG_SHOP,
+	SHIP_ROOM_FLAGS_COCKPIT, SHIP_ROOM_FLAGS_ENGINEROOM, SHIP_ROOM_FLAGS_RAMP, SHIP_ROOM_FLAGS_TURRET, SHIP_ROOM_FLAGS_CARGO,
+}
+
 type RoomData struct {
 	Id        uint                     `yaml:"id"`
 	ship      uint                     `yaml:"shipId,omitempty"`
@@ -81,6 +155,9 @@ type RoomData struct {
 	ExitFlags map[string]*RoomExitFlag `yaml:"exflags,omitempty"`
 	Flags     []string                 `yaml:"flags,flow,omitempty"`
 	RoomProgs map[string]string        `yaml:"roomProgs,omitempty"`
+	Extras    []ExtraDesc              `yaml:"extras,omitempty"`      // scenery that can be looked at.
+	Coords    []int                    `yaml:"coords,flow,omitempty"` // x, y, z on the map. Rooms without them are laid out from their exits.
+	Sector    string                   `yaml:"sector,omitempty"`      // SECTOR_* of the room, the area's if empty.
 	Area      *AreaData                `yaml:"-"`
 	Items     []Item                   `yaml:"-"`
 }
@@ -95,6 +172,11 @@ type Room interface {
 	GetRoomProgs() map[string]string
 	GetArea() Area
 	GetObjects() []Item
+	GetEntities() []Entity
+	GetExit(direction string) Exit
+	SetExit(direction string, target Room)
+	SetExitFlags(direction string, flags *RoomExitFlag)
+	RemoveExit(direction string)
 }
 
 type Exit interface {
@@ -102,21 +184,726 @@ type Exit interface {
 	GetRoom() Room
 	GetShip() Ship
 	GetTarget() Room
+	IsDoor() bool
 	IsClosed() bool
 	IsLocked() bool
+	IsHidden() bool
 	GetKeyId() uint
+	GetName() string
+}
+
+// ExitData is a view of one exit of a room, the exit itself lives in the room's Exits and ExitFlags maps.
+type ExitData struct {
+	room      *RoomData
+	direction string
+}
+
+// The order exits are listed in, anything else comes after these alphabetically.
+var directions = []string{"north", "northeast", "east", "southeast", "south", "southwest", "west", "northwest", "up", "down"}
+
+// ExtraDesc is something a description mentions that can be looked at, the sign or the terminal.
+type ExtraDesc struct {
+	Keywords []string `yaml:"keywords,flow"`
+	Desc     string   `yaml:"desc"`
+}
+
+// extra_desc_find returns the extra description with a keyword starting with keyword, nil if none do.
+func extra_desc_find(extras []ExtraDesc, keyword string) *ExtraDesc {
+	keyword = strings.ToLower(keyword)
+	if keyword == "" {
+		return nil
+	}
+	for i := range extras {
+		for _, k := range extras[i].Keywords {
+			if strings.HasPrefix(strings.ToLower(k), keyword) {
+				return &extras[i]
+			}
+		}
+	}
+	return nil
+}
+
+// extra_desc_set replaces the extra description with any of keywords, an empty desc just removes it.
+func extra_desc_set(extras []ExtraDesc, keywords []string, desc string) []ExtraDesc {
+	ret := make([]ExtraDesc, 0)
+	for _, e := range extras {
+		keep := true
+		for _, k := range keywords {
+			if slice_contains_string(e.Keywords, k) {
+				keep = false
+			}
+		}
+		if keep {
+			ret = append(ret, e)
+		}
+	}
+	if desc != "" {
+		ret = append(ret, ExtraDesc{Keywords: keywords, Desc: desc})
+	}
+	return ret
+}
+
+// extra_desc_look finds the scenery the entity means, in the room first then on the items about.
+func extra_desc_look(entity Entity, room *RoomData, keyword string) *ExtraDesc {
+	if e := extra_desc_find(room.Extras, keyword); e != nil {
+		return e
+	}
+	items := make([]Item, 0)
+	items = append(items, room.Items...)
+	ch := entity.GetCharData()
+	for _, i := range ch.Inventory {
+		items = append(items, i)
+	}
+	for _, i := range ch.Equipment {
+		items = append(items, i)
+	}
+	for _, i := range items {
+		if i == nil || i.GetData() == nil {
+			continue
+		}
+		if e := extra_desc_find(i.GetData().Extras, keyword); e != nil {
+			return e
+		}
+	}
+	return nil
 }
 
 type RoomExitFlag struct {
-	Locked bool `yaml:"locked,omitempty"`
-	Closed bool `yaml:"closed,omitempty"`
-	Key    uint `yaml:"key,omitempty"`
+	Locked     bool   `yaml:"locked,omitempty"`
+	Closed     bool   `yaml:"closed,omitempty"`
+	Key        uint   `yaml:"key,omitempty"`
+	Name       string `yaml:"name,omitempty"`       // what the door is called, "blast door", "hatch", defaults to door.
+	Hidden     bool   `yaml:"hidden,omitempty"`     // hidden exits can't be seen or used until someone searches for them.
+	Difficulty uint   `yaml:"difficulty,omitempty"` // how hard the lock is to pick out of 100, 0 can't be picked.
+	Keypad     bool   `yaml:"keypad,omitempty"`     // keypads are picked with electronics, other locks with lockpicking.
+	found      bool   // a hidden exit someone has found, until the area resets.
 }
 
 func (e *RoomExitFlag) String() string {
 	return sprintf("closed: %v, locked: %v, key: %d", e.Closed, e.Locked, e.Key)
 }
 
+// GetName returns what the door is called.
+func (e *RoomExitFlag) GetName() string {
+	if e.Name == "" {
+		return "door"
+	}
+	return e.Name
+}
+
+// GetPickSkill returns the skill used to pick the lock.
+func (e *RoomExitFlag) GetPickSkill() string {
+	if e.Keypad {
+		return "electronics"
+	}
+	return "lockpicking"
+}
+
+// Delete removes the area from the game and from the database.
+func (a *AreaData) Delete() error {
+	DB().RemoveArea(a)
+	return DB().store.DeleteArea(a)
+}
+
+// DeleteRoom removes the room with the vnum id from the area and the game.
+func (a *AreaData) DeleteRoom(id uint) {
+	for i := range a.Rooms {
+		if a.Rooms[i].Id == id {
+			a.Rooms = append(a.Rooms[:i], a.Rooms[i+1:]...)
+			DB().DeleteRoom(id)
+			return
+		}
+	}
+}
+
+func (a *AreaData) DeleteMobSpawn(index uint) {
+	if int(index) >= len(a.Mobs) {
+		return
+	}
+	a.Mobs = append(a.Mobs[:index], a.Mobs[index+1:]...)
+}
+
+func (a *AreaData) DeleteObjectSpawn(index uint) {
+	if int(index) >= len(a.Items) {
+		return
+	}
+	a.Items = append(a.Items[:index], a.Items[index+1:]...)
+}
+
+func (a *AreaData) GetName() string {
+	return a.Name
+}
+
+func (a *AreaData) GetAuthor() string {
+	return a.Author
+}
+
+func (a *AreaData) GetLevels() []uint16 {
+	return a.Levels
+}
+
+func (a *AreaData) GetReset() uint {
+	return a.ResetInterval
+}
+
+func (a *AreaData) GetResetMsg() string {
+	return a.ResetMsg
+}
+
+// GetRooms returns the live rooms of the area.
+// GetVnums returns the [min, max] range of VNUM_* kind vnums the area owns, nil if it doesn't own any.
+func (a *AreaData) GetVnums(kind string) []uint {
+	var r []uint
+	switch kind {
+	case VNUM_ROOM:
+		r = a.RoomVnums
+	case VNUM_MOB:
+		r = a.MobVnums
+	case VNUM_ITEM:
+		r = a.ItemVnums
+	}
+	if len(r) != 2 {
+		return nil
+	}
+	return r
+}
+
+func (a *AreaData) GetRooms() []Room {
+	ret := make([]Room, 0, len(a.Rooms))
+	for i := range a.Rooms {
+		if r := DB().GetRoom(a.Rooms[i].Id, 0); r != nil {
+			ret = append(ret, r)
+		}
+	}
+	return ret
+}
+
+func (a *AreaData) GetMobSpawns() []MobSpawn {
+	return a.Mobs
+}
+
+func (a *AreaData) GetObjectSpawns() []ItemSpawn {
+	return a.Items
+}
+
+// GetEntities returns every entity in the area's rooms.
+func (a *AreaData) GetEntities() []Entity {
+	ids := make(map[uint]bool)
+	for i := range a.Rooms {
+		ids[a.Rooms[i].Id] = true
+	}
+	ret := make([]Entity, 0)
+	db := DB()
+	db.Lock()
+	defer db.Unlock()
+	for _, e := range db.entities {
+		if e == nil {
+			continue
+		}
+		if e.ShipId() == 0 && ids[e.RoomId()] {
+			ret = append(ret, e)
+		}
+	}
+	return ret
+}
+
+// Reset puts the area back the way it was built, doors, litter and spawns. See [area_reset] for the timer.
+func (a *AreaData) Reset() error {
+	db := DB()
+	var ret error
+	for _, r := range a.Rooms {
+		room := db.GetRoom(r.Id, 0)
+		if room == nil {
+			ret = Err("room %d doesn't exist, resetting area %s", r.Id, a.Name)
+			ErrorCheck(ret)
+			continue
+		}
+		room.ExitFlags = room_copy_exit_flags(r.ExitFlags)
+		rem_items := make([]Item, 0)
+		for _, i := range room.Items {
+			if i != nil {
+				if i.IsCorpse() && i.GetData().Decay.IsZero() {
+					// corpses with a decay timer rot away on their own, see world_decay.
+					rem_items = append(rem_items, i)
+				}
+				if i.IsContainer() {
+					if i.GetData().Type == ITEM_TYPE_TRASH_BIN {
+						i.GetData().Items = make([]Item, 0)
+					}
+				}
+			}
+		}
+		for _, i := range rem_items {
+			room.RemoveItem(i)
+		}
+
+		room.SendToRoom(sprintf("\r\n&d%s&d\r\n", a.ResetMsg))
+	}
+	for i := range a.Doors {
+		if err := area_reset_door(&a.Doors[i]); err != nil {
+			ret = Err("%v, resetting area %s", err, a.Name)
+			ErrorCheck(ret)
+		}
+	}
+	for i := range a.Items {
+		if err := area_reset_item(&a.Items[i]); err != nil {
+			ret = Err("%v, resetting area %s", err, a.Name)
+			ErrorCheck(ret)
+		}
+	}
+	for i := range a.Mobs {
+		if err := area_reset_mob(&a.Mobs[i]); err != nil {
+			ret = Err("%v, resetting area %s", err, a.Name)
+			ErrorCheck(ret)
+		}
+	}
+	return ret
+}
+
+// reset_chance rolls a percent chance, 0 always passes.
+func reset_chance(chance uint) bool {
+	return chance == 0 || chance >= 100 || uint(roll_dice("1d100")) <= chance
+}
+
+// reset_ship_away is true when the spawn is aboard a ship that's out in space, ship spawns wait until it lands again.
+func reset_ship_away(ship uint) bool {
+	if ship == 0 {
+		return false
+	}
+	s := DB().GetShip(ship)
+	return s != nil && s.GetData().InSpace
+}
+
+// area_reset_door opens, closes or locks a door, and the other side of it.
+func area_reset_door(door *DoorReset) error {
+	if reset_ship_away(door.Ship) {
+		return nil
+	}
+	db := DB()
+	room := db.GetRoom(door.Room, door.Ship)
+	if room == nil {
+		return Err("door reset in room %d, room doesn't exist", door.Room)
+	}
+	if room.GetExit(door.Direction) == nil {
+		return Err("door reset in room %d, there's no exit %s", door.Room, door.Direction)
+	}
+	set := func(r *RoomData, dir string) {
+		flags := &RoomExitFlag{}
+		if f, ok := r.ExitFlags[dir]; ok && f != nil {
+			*flags = *f
+		}
+		flags.Closed = door.State != DOOR_OPEN
+		flags.Locked = door.State == DOOR_LOCKED
+		r.SetExitFlags(dir, flags)
+	}
+	set(room, door.Direction)
+	if to := db.GetRoom(room.Exits[door.Direction], door.Ship); to != nil {
+		back := direction_reverse(door.Direction)
+		if to.Exits[back] == room.Id {
+			set(to, back)
+		}
+	}
+	return nil
+}
+
+// reset_item_make makes a copy of the item prototype, filling it with its contents.
+func reset_item_make(ri *ResetItem) Item {
+	proto := DB().GetItem(ri.Item)
+	if proto == nil {
+		return nil
+	}
+	item := item_clone(proto)
+	reset_item_fill(item, ri.Contents)
+	return item
+}
+
+// reset_item_fill puts whatever's missing from contents into the container.
+func reset_item_fill(container Item, contents []ResetItem) {
+	if len(contents) == 0 || !container.IsContainer() {
+		return
+	}
+	have := make(map[uint]int)
+	for _, i := range container.GetData().Items {
+		if i != nil {
+			have[item_prototype_id(i)]++
+		}
+	}
+	for idx := range contents {
+		ri := &contents[idx]
+		if have[ri.Item] > 0 {
+			have[ri.Item]--
+			continue
+		}
+		if !reset_chance(ri.Chance) {
+			continue
+		}
+		if item := reset_item_make(ri); item != nil {
+			container.GetData().AddItem(item)
+		}
+	}
+}
+
+// area_reset_item puts the item in its room if there's room for it, and tops up its contents.
+func area_reset_item(spawn *ItemSpawn) error {
+	if reset_ship_away(spawn.Ship) {
+		return nil
+	}
+	db := DB()
+	room := db.GetRoom(spawn.Room, spawn.Ship)
+	proto := db.GetItem(spawn.Item)
+	if room == nil || proto == nil {
+		return Err("item spawn %d in room %d can't be reset", spawn.Item, spawn.Room)
+	}
+	max_room := spawn.MaxRoom
+	if max_room == 0 {
+		max_room = 1
+	}
+	in_room := make([]Item, 0)
+	for _, i := range room.Items {
+		if i != nil && item_prototype_id(i) == spawn.Item {
+			in_room = append(in_room, i)
+		}
+	}
+	for _, i := range in_room {
+		reset_item_fill(i, spawn.Contents)
+	}
+	if uint(len(in_room)) >= max_room {
+		return nil
+	}
+	if spawn.MaxWorld > 0 && db.CountItems(spawn.Item) >= spawn.MaxWorld {
+		return nil
+	}
+	if !reset_chance(spawn.Chance) {
+		return nil
+	}
+	item := item_clone(proto)
+	reset_item_fill(item, spawn.Contents)
+	room.AddItem(item)
+	return nil
+}
+
+// area_reset_mob spawns the mob if the last one it spawned is dead, kitted out with its equipment.
+func area_reset_mob(spawn *MobSpawn) error {
+	if reset_ship_away(spawn.Ship) {
+		return nil
+	}
+	db := DB()
+	mob := db.GetMob(spawn.Mob) // grabs the mob template
+	if mob == nil {
+		return Err("mob spawn %d in room %d can't be reset", spawn.Mob, spawn.Room)
+	}
+	if db.GetRoom(spawn.Room, spawn.Ship) == nil {
+		return Err("mob spawn %d in room %d can't be reset, room doesn't exist", spawn.Mob, spawn.Room)
+	}
+	if spawn.entity != nil { // checks to see if we have a managed entity
+		if spawn.entity.GetCharData().State == ENTITY_STATE_DEAD { // is it dead?
+			spawn.entity = nil // nil it out so we create a new one...
+		} else {
+			return nil
+		}
+	}
+	if spawn.MaxWorld > 0 && db.CountMobs(spawn.Mob, 0, 0) >= spawn.MaxWorld {
+		return nil
+	}
+	if spawn.MaxRoom > 0 && db.CountMobs(spawn.Mob, spawn.Room, spawn.Ship) >= spawn.MaxRoom {
+		return nil
+	}
+	if !reset_chance(spawn.Chance) {
+		return nil
+	}
+	spawn.entity = db.SpawnEntity(mob)
+	ch := spawn.entity.GetCharData()
+	ch.Room = spawn.Room
+	ch.Home = spawn.Room
+	ch.Ship = spawn.Ship
+	for idx := range spawn.Equip {
+		ri := &spawn.Equip[idx]
+		if !reset_chance(ri.Chance) {
+			continue
+		}
+		item := reset_item_make(ri)
+		if item == nil {
+			continue
+		}
+		if slot := item_wear_slot(item); slot != "" {
+			ch.Equipment[slot] = item.GetData()
+		} else {
+			ch.Inventory = append(ch.Inventory, item.GetData())
+		}
+	}
+	for idx := range spawn.Give {
+		ri := &spawn.Give[idx]
+		if !reset_chance(ri.Chance) {
+			continue
+		}
+		if item := reset_item_make(ri); item != nil {
+			ch.Inventory = append(ch.Inventory, item.GetData())
+		}
+	}
+	for _, e := range db.GetEntitiesInRoom(ch.Room, ch.Ship) {
+		if e == nil {
+			continue
+		}
+		if e.GetCharData().Id != ch.Id {
+			ch.AI.OnGreet(e)
+		}
+	}
+	return nil
+}
+
+// Save writes the area, and the mobs and items it spawns, to the database.
+func (a *AreaData) Save() error {
+	return DB().SaveArea(a)
+}
+
+func (a *AreaData) SetName(name string) {
+	a.Name = name
+}
+
+func (a *AreaData) SetAuthor(author string) {
+	a.Author = author
+}
+
+func (a *AreaData) SetReset(seconds uint) {
+	a.ResetInterval = seconds
+}
+
+func (a *AreaData) SetResetMsg(message string) {
+	a.ResetMsg = message
+}
+
+// SetRooms replaces every room in the area, the rooms are put into the game as well.
+func (a *AreaData) SetRooms(rooms []Room) {
+	a.Rooms = make([]RoomData, 0, len(rooms))
+	for _, room := range rooms {
+		a.SetRoom(room.GetId(), room)
+	}
+}
+
+// SetVnums gives the area the [min, max] range of VNUM_* kind vnums, 0 0 takes them away.
+func (a *AreaData) SetVnums(kind string, min uint, max uint) {
+	r := []uint{min, max}
+	if min == 0 && max == 0 {
+		r = nil
+	}
+	switch kind {
+	case VNUM_ROOM:
+		a.RoomVnums = r
+	case VNUM_MOB:
+		a.MobVnums = r
+	case VNUM_ITEM:
+		a.ItemVnums = r
+	}
+}
+
+// SetRoom copies the room into the area, so it's saved with it, and puts it into the game as vnum id.
+func (a *AreaData) SetRoom(id uint, room Room) {
+	r, ok := room.(*RoomData)
+	if !ok || r == nil {
+		return
+	}
+	r.Id = id
+	r.Area = a
+	DB().SetRoom(id, r)
+	proto := *r
+	proto.ExitFlags = room_copy_exit_flags(r.ExitFlags)
+	for i := range a.Rooms {
+		if a.Rooms[i].Id == id {
+			a.Rooms[i] = proto
+			return
+		}
+	}
+	a.Rooms = append(a.Rooms, proto)
+}
+
+func (a *AreaData) SetMobSpawns(mob_spawns []MobSpawn) {
+	a.Mobs = mob_spawns
+}
+
+// SetMobSpawn sets the spawn at index, an index past the end adds a new spawn.
+func (a *AreaData) SetMobSpawn(index uint, mob_id uint, room_id uint) {
+	if int(index) >= len(a.Mobs) {
+		a.Mobs = append(a.Mobs, MobSpawn{Mob: mob_id, Room: room_id})
+		return
+	}
+	a.Mobs[index] = MobSpawn{Mob: mob_id, Room: room_id}
+}
+
+func (a *AreaData) SetObjectSpawns(obj_spawns []ItemSpawn) {
+	a.Items = obj_spawns
+}
+
+// SetObjectSpawn sets the spawn at index, an index past the end adds a new spawn.
+func (a *AreaData) SetObjectSpawn(index uint, obj_id uint, room_id uint) {
+	if int(index) >= len(a.Items) {
+		a.Items = append(a.Items, ItemSpawn{Item: obj_id, Room: room_id})
+		return
+	}
+	a.Items[index] = ItemSpawn{Item: obj_id, Room: room_id}
+}
+
+func (r *RoomData) GetId() uint {
+	return r.Id
+}
+
+// GetShip returns the ship the room is in, nil for planet rooms.
+func (r *RoomData) GetShip() Ship {
+	if r.ship == 0 {
+		return nil
+	}
+	return DB().GetShip(r.ship)
+}
+
+func (r *RoomData) GetName() string {
+	return r.Name
+}
+
+func (r *RoomData) GetDesc() string {
+	return r.Desc
+}
+
+// GetExits returns the room's exits, in the order they should be listed.
+func (r *RoomData) GetExits() []Exit {
+	ret := make([]Exit, 0, len(r.Exits))
+	for _, dir := range directions {
+		if _, ok := r.Exits[dir]; ok {
+			ret = append(ret, &ExitData{room: r, direction: dir})
+		}
+	}
+	others := make([]string, 0)
+	for dir := range r.Exits {
+		if !slice_contains_string(directions, dir) {
+			others = append(others, dir)
+		}
+	}
+	sort.Strings(others)
+	for _, dir := range others {
+		ret = append(ret, &ExitData{room: r, direction: dir})
+	}
+	return ret
+}
+
+// GetExit returns the exit in direction, nil if there isn't one.
+func (r *RoomData) GetExit(direction string) Exit {
+	if !r.HasExit(direction) {
+		return nil
+	}
+	return &ExitData{room: r, direction: direction}
+}
+
+// SetExit makes an exit in direction to the target room. Doors on an existing exit are kept.
+func (r *RoomData) SetExit(direction string, target Room) {
+	if r.Exits == nil {
+		r.Exits = make(map[string]uint)
+	}
+	r.Exits[direction] = target.GetId()
+}
+
+// SetExitFlags makes the exit in direction a door, nil flags removes the door.
+func (r *RoomData) SetExitFlags(direction string, flags *RoomExitFlag) {
+	if flags == nil {
+		delete(r.ExitFlags, direction)
+		return
+	}
+	if r.ExitFlags == nil {
+		r.ExitFlags = make(map[string]*RoomExitFlag)
+	}
+	r.ExitFlags[direction] = flags
+}
+
+// RemoveExit removes the exit in direction, and its door.
+func (r *RoomData) RemoveExit(direction string) {
+	delete(r.Exits, direction)
+	delete(r.ExitFlags, direction)
+}
+
+func (r *RoomData) GetFlags() []string {
+	return r.Flags
+}
+
+func (r *RoomData) GetRoomProgs() map[string]string {
+	return r.RoomProgs
+}
+
+// GetArea returns the area the room is in, nil for ship rooms.
+func (r *RoomData) GetArea() Area {
+	if r.Area == nil {
+		return nil
+	}
+	return r.Area
+}
+
+func (r *RoomData) GetObjects() []Item {
+	return r.Items
+}
+
+func (e *ExitData) GetDirection() string {
+	return e.direction
+}
+
+func (e *ExitData) GetRoom() Room {
+	return e.room
+}
+
+func (e *ExitData) GetShip() Ship {
+	return e.room.GetShip()
+}
+
+// GetTarget returns the room the exit leads to, nil if it leads nowhere.
+func (e *ExitData) GetTarget() Room {
+	id, ok := e.room.Exits[e.direction]
+	if !ok {
+		return nil
+	}
+	target := DB().GetRoom(id, e.room.ship)
+	if target == nil {
+		return nil
+	}
+	return target
+}
+
+func (e *ExitData) flags() *RoomExitFlag {
+	return e.room.GetExitFlags(e.direction)
+}
+
+// IsDoor is true if the exit has a door, which may be open.
+func (e *ExitData) IsDoor() bool {
+	return e.flags() != nil
+}
+
+func (e *ExitData) IsClosed() bool {
+	f := e.flags()
+	return f != nil && f.Closed
+}
+
+func (e *ExitData) IsLocked() bool {
+	f := e.flags()
+	return f != nil && f.Locked
+}
+
+// IsHidden is true if the exit is hidden and nobody has found it yet.
+func (e *ExitData) IsHidden() bool {
+	f := e.flags()
+	return f != nil && f.Hidden && !f.found
+}
+
+// GetName returns what the door is called, empty if there's no door.
+func (e *ExitData) GetName() string {
+	f := e.flags()
+	if f == nil {
+		return ""
+	}
+	return f.GetName()
+}
+
+func (e *ExitData) GetKeyId() uint {
+	f := e.flags()
+	if f == nil {
+		return 0
+	}
+	return f.Key
+}
+
 func (r *RoomData) String() string {
 	return fmt.Sprintf("ROOM:[%d-%s]", r.Id, r.Name)
 }
@@ -145,27 +932,17 @@ func (r *RoomData) RemoveItem(item Item) {
 			idx = id
 		}
 	}
-	ret := make([]Item, len(r.Items)-1)
+	if idx < 0 {
+		return
+	}
+	ret := make([]Item, 0, len(r.Items)-1)
 	ret = append(ret, r.Items[:idx]...)
 	ret = append(ret, r.Items[idx+1:]...)
 	r.Items = ret
 }
 
 func (r *RoomData) FindItem(keyword string) Item {
-	for id := range r.Items {
-		i := r.Items[id]
-		if i == nil {
-			continue
-		}
-		keys := i.GetKeywords()
-		for k := range keys {
-			key := keys[k]
-			if strings.HasPrefix(key, keyword) {
-				return i
-			}
-		}
-	}
-	return nil
+	return target_item(r.Items, keyword)
 }
 
 func (r *RoomData) HasFlag(flag string) bool {
@@ -176,6 +953,14 @@ func (r *RoomData) HasFlag(flag string) bool {
 	}
 	return false
 }
+
+// IsFlagged is true if the room has the flag, or the area it's in does.
+func (r *RoomData) IsFlagged(flag string) bool {
+	if r.HasFlag(flag) {
+		return true
+	}
+	return r.Area != nil && slice_contains_string(r.Area.Flags, flag)
+}
 func (r *RoomData) RemoveFlag(flag string) {
 	index := -1
 	for i, f := range r.Flags {
@@ -224,6 +1009,7 @@ func (r *RoomData) OpenDoor(entity Entity, direction string, silent bool) {
 	if flags == nil {
 		return
 	}
+	name := flags.GetName()
 	if flags.Closed {
 		if flags.Locked && entity != nil {
 			if !r.UnlockDoor(entity, direction, silent) {
@@ -236,15 +1022,15 @@ func (r *RoomData) OpenDoor(entity Entity, direction string, silent bool) {
 		flags.Closed = false
 		flags.Locked = false // just in-case this is called with a nil entity, the system wants to open the door.
 		if entity != nil && !silent {
-			entity.Send("\r\nThe door slides open.\r\n")
+			entity.Send("\r\nThe %s slides open.\r\n", name)
 		}
 		// schedule a closing of the door 15 seconds from now
 		ScheduleFunc(func() {
 			flags.Closed = true
-			r.SendToRoom(sprintf("\r\nThe door to the %s slides closed.\r\n", direction))
+			r.SendToRoom(sprintf("\r\nThe %s to the %s slides closed.\r\n", name, direction))
 		}, false, 15)
 
-		r.SendToOthers(entity, sprintf("\r\nThe door to the %s slides open.\r\n", direction))
+		r.SendToOthers(entity, sprintf("\r\nThe %s to the %s slides open.\r\n", name, direction))
 		tflags := to_room.GetExitFlags(direction_reverse(direction))
 		if tflags != nil {
 			if tflags.Closed {
@@ -253,11 +1039,11 @@ func (r *RoomData) OpenDoor(entity Entity, direction string, silent bool) {
 					tflags.Locked = false
 				}
 				tflags.Closed = false
-				to_room.SendToOthers(entity, sprintf("\r\nThe door to the %s slides open.\r\n", direction_reverse(direction)))
+				to_room.SendToOthers(entity, sprintf("\r\nThe %s to the %s slides open.\r\n", tflags.GetName(), direction_reverse(direction)))
 				ScheduleFunc(func() {
 					tflags.Closed = true
 					tflags.Locked = was_locked
-					to_room.SendToRoom(sprintf("\r\nThe door to the %s slides closed.\r\n", direction_reverse(direction)))
+					to_room.SendToRoom(sprintf("\r\nThe %s to the %s slides closed.\r\n", tflags.GetName(), direction_reverse(direction)))
 				}, false, 15)
 			}
 		}
@@ -281,15 +1067,15 @@ func (r *RoomData) CloseDoor(entity Entity, direction string, silent bool) {
 	if !flags.Closed {
 		flags.Closed = true
 		if entity != nil && !silent {
-			entity.Send("\r\nThe door slides closed.\r\n")
+			entity.Send("\r\nThe %s slides closed.\r\n", flags.GetName())
 		}
-		r.SendToRoom(sprintf("\r\nThe door to the %s slides closed.\r\n", direction))
+		r.SendToOthers(entity, sprintf("\r\nThe %s to the %s slides closed.\r\n", flags.GetName(), direction))
 		tflags := to_room.GetExitFlags(direction_reverse(direction))
 		if tflags != nil {
 			if !tflags.Closed {
 				tflags.Closed = true
 				tflags.Locked = (tflags.Key != 0) // keys set on doors are always lockable.
-				to_room.SendToRoom(sprintf("\r\nThe door to the %s slides closed.\r\n", direction_reverse(direction)))
+				to_room.SendToRoom(sprintf("\r\nThe %s to the %s slides closed.\r\n", tflags.GetName(), direction_reverse(direction)))
 			}
 		}
 	} else {
@@ -316,21 +1102,10 @@ func (r *RoomData) UnlockDoor(entity Entity, direction string, silent bool) bool
 				return false
 			}
 			if !silent {
-				entity.Send("\r\n&YYou hear a clunk as you unlock the door.&d\r\n")
+				entity.Send("\r\n&YYou hear a clunk as you unlock the %s.&d\r\n", flags.GetName())
 			}
-			ScheduleFunc(func() {
-				flags.Locked = true
-			}, false, 15)
-		}
-		flags.Locked = false
-		tflags := to_room.GetExitFlags(direction_reverse(direction))
-		was_locked := (tflags.Key != 0) // keys set on doors are always lockable...
-		if tflags.Locked {
-			tflags.Locked = false
-			ScheduleFunc(func() {
-				tflags.Locked = was_locked
-			}, false, 15)
 		}
+		r.unlock_door(direction, entity != nil)
 		return true
 	}
 	if entity != nil && !silent {
@@ -339,6 +1114,101 @@ func (r *RoomData) UnlockDoor(entity Entity, direction string, silent bool) bool
 	return false
 }
 
+// PickDoor tries to pick the lock of the door in direction with the entity's lockpicking, or electronics for a
+// keypad. Returns true if the lock was picked.
+func (r *RoomData) PickDoor(entity Entity, direction string) bool {
+	flags := r.GetExitFlags(direction)
+	if flags == nil || !flags.Locked {
+		entity.Send("\r\n&RIt's not locked.&d\r\n")
+		return false
+	}
+	if flags.Difficulty == 0 {
+		entity.Send("\r\n&RYou can't find any way to pick the lock on the %s.&d\r\n", flags.GetName())
+		return false
+	}
+	skill := flags.GetPickSkill()
+	ch := entity.GetCharData()
+	chance := 50 + entity_get_skill_value(ch, skill) - int(flags.Difficulty)
+	if chance < 5 {
+		chance = 5
+	}
+	if chance > 95 {
+		chance = 95
+	}
+	if roll_dice("1d10") == 10 {
+		entity_add_skill_value(entity, skill, 1)
+	}
+	if roll_dice("1d100") > chance {
+		if flags.Keypad {
+			entity.Send("\r\n&RThe keypad on the %s buzzes angrily at you.&d\r\n", flags.GetName())
+		} else {
+			entity.Send("\r\n&RYou fiddle with the lock on the %s but it won't give.&d\r\n", flags.GetName())
+		}
+		r.SendToOthers(entity, sprintf("\r\n%s fiddles with the lock on the %s to the %s.\r\n", ch.Name, flags.GetName(), direction))
+		return false
+	}
+	if flags.Keypad {
+		entity.Send("\r\n&YThe keypad on the %s chirps and you hear the lock release.&d\r\n", flags.GetName())
+	} else {
+		entity.Send("\r\n&YWith a quiet click you pick the lock on the %s.&d\r\n", flags.GetName())
+	}
+	r.unlock_door(direction, true)
+	return true
+}
+
+// unlock_door unlocks both sides of the door in direction, relocking them after 15 seconds if relock is set.
+func (r *RoomData) unlock_door(direction string, relock bool) {
+	flags := r.GetExitFlags(direction)
+	if flags == nil {
+		return
+	}
+	flags.Locked = false
+	if relock {
+		ScheduleFunc(func() {
+			flags.Locked = true
+		}, false, 15)
+	}
+	to_room := r.GetExitRoom(direction)
+	if to_room == nil {
+		return
+	}
+	tflags := to_room.GetExitFlags(direction_reverse(direction))
+	if tflags == nil {
+		return
+	}
+	was_locked := (tflags.Key != 0) // keys set on doors are always lockable...
+	if tflags.Locked {
+		tflags.Locked = false
+		ScheduleFunc(func() {
+			tflags.Locked = was_locked
+		}, false, 15)
+	}
+}
+
+// SearchExits looks for the hidden exits in the room, the entity's perception decides if they turn up.
+// Returns the exits that were found.
+func (r *RoomData) SearchExits(entity Entity) []Exit {
+	ret := make([]Exit, 0)
+	ch := entity.GetCharData()
+	for _, exit := range r.GetExits() {
+		if !exit.IsHidden() {
+			continue
+		}
+		if roll_dice("1d10") == 10 {
+			entity_add_skill_value(entity, "perception", 1)
+		}
+		chance := 25 + entity_get_skill_value(ch, "perception")
+		if chance > 95 {
+			chance = 95
+		}
+		if roll_dice("1d100") <= chance {
+			r.GetExitFlags(exit.GetDirection()).found = true
+			ret = append(ret, exit)
+		}
+	}
+	return ret
+}
+
 func (r *RoomData) LockDoor(entity Entity, direction string, key Item) {
 	flags := r.GetExitFlags(direction)
 	if flags == nil {
@@ -350,7 +1220,7 @@ func (r *RoomData) LockDoor(entity Entity, direction string, key Item) {
 	flags.Locked = true
 	flags.Key = key.GetTypeId()
 	if entity != nil {
-		entity.Send("\r\n&YWith a clunk you lock the door with %s %s.&d\r\n", key.GetData().Name, key.GetData().Name)
+		entity.Send("\r\n&YWith a clunk you lock the %s with %s.&d\r\n", flags.GetName(), key.GetData().Name)
 	}
 }
 
@@ -385,108 +1255,177 @@ func (r *RoomData) GetEntities() []Entity {
 func (r *RoomData) GetShips() []Ship {
 	return DB().GetShipsInRoom(r.Id)
 }
-func room_get_blocked_exit_flags(exitFlags *RoomExitFlag) (locked bool, closed bool) {
-	locked = false
-	closed = false
-	locked = exitFlags.Locked
-	closed = exitFlags.Closed
-	return locked, closed
-}
-func room_get_exit_status(exitFlags *RoomExitFlag) string {
+
+// room_get_exit_status is the (closed) (locked) shown next to an exit.
+func room_get_exit_status(exit Exit) string {
 	ret := ""
-	locked, closed := room_get_blocked_exit_flags(exitFlags)
-	if closed {
+	if exit.IsClosed() {
 		ret += "(closed) "
 	}
-	if locked {
+	if exit.IsLocked() {
 		ret += "(locked) "
 	}
+	if exit.IsHidden() {
+		ret += "(hidden) "
+	}
 	return ret
 }
 
-func area_reset(area *AreaData) {
-	db := DB()
-	if area == nil {
-		return
+// room_exit_visible is true if the entity can see the exit, only immortals see hidden exits nobody has found.
+func room_exit_visible(entity Entity, exit Exit) bool {
+	if !exit.IsHidden() {
+		return true
 	}
-	for _, r := range area.Rooms {
-		room_id := r.Id
-		room := db.GetRoom(room_id, 0)
-		if room == nil {
-			log.Printf("Error: roomId %d doesn't exist! area_reset(%s)", room_id, area.Name)
-			continue
-		}
-		for dir, f := range r.ExitFlags {
-			room.ExitFlags[dir] = f
-		}
-		rem_items := make([]Item, 0)
-		for _, i := range room.Items {
-			if i != nil {
-				if i.IsCorpse() {
-					rem_items = append(rem_items, i)
-				}
-				if i.IsContainer() {
-					if i.GetData().Type == ITEM_TYPE_TRASH_BIN {
-						i.GetData().Items = make([]Item, 0)
-					}
-				}
+	if entity != nil && entity.IsPlayer() {
+		return entity.(*PlayerProfile).Priv >= 100
+	}
+	return false
+}
+
+// room_link makes exits both ways between room and to_room, with a closed door if closed is set.
+func room_link(room Room, direction string, to_room Room, closed bool) {
+	room.SetExit(direction, to_room)
+	to_room.SetExit(direction_reverse(direction), room)
+	if closed {
+		room.SetExitFlags(direction, &RoomExitFlag{Closed: true})
+		to_room.SetExitFlags(direction_reverse(direction), &RoomExitFlag{Closed: true})
+	}
+}
+
+// room_unlink removes the exit in direction and the one coming back the other way.
+func room_unlink(room Room, direction string) {
+	if exit := room.GetExit(direction); exit != nil {
+		if to_room := exit.GetTarget(); to_room != nil {
+			if back := to_room.GetExit(direction_reverse(direction)); back != nil && back.GetTarget() == room {
+				to_room.RemoveExit(direction_reverse(direction))
+				room_update(to_room)
 			}
 		}
-		for _, i := range rem_items {
-			room.RemoveItem(i)
-		}
+	}
+	room.RemoveExit(direction)
+	room_update(room)
+}
 
-		room.SendToRoom(sprintf("\r\n&d%s&d\r\n", area.ResetMsg))
+// room_update copies a changed room back into its ship or area, so the change is saved along with it.
+func room_update(room Room) {
+	if ship := room.GetShip(); ship != nil {
+		ship.GetData().Rooms[room.GetId()] = room.(*RoomData)
+		return
 	}
-	for _, spawn := range area.Items {
-		room := db.GetRoom(spawn.Room, 0)
-		item := db.GetItem(spawn.Item)
-		exists := false
-		for _, i := range room.Items {
-			if i != nil {
-				if i.GetData().Name == item.GetData().Name {
-					exists = true
-				}
-			}
+	if area := room.GetArea(); area != nil {
+		area.SetRoom(room.GetId(), room)
+	}
+}
+
+// vnum_in_range is true if vnum is in the [min, max] range r.
+func vnum_in_range(r []uint, vnum uint) bool {
+	return len(r) == 2 && r[0] <= vnum && vnum <= r[1]
+}
+
+// vnum_ranges_overlap is true if the [min, max] ranges a and b share any vnums.
+func vnum_ranges_overlap(a []uint, b []uint) bool {
+	return len(a) == 2 && len(b) == 2 && a[0] <= b[1] && b[0] <= a[1]
+}
+
+// area_vnum_conflict returns an error if area's vnum ranges are backwards, overlap one of the other
+// areas, or if any of its rooms are outside its room range.
+func area_vnum_conflict(area *AreaData, areas []*AreaData) error {
+	for _, kind := range vnum_kinds {
+		r := area.GetVnums(kind)
+		if r == nil {
+			continue
 		}
-		if !exists {
-			room.AddItem(item_clone(item))
+		if r[0] > r[1] {
+			return Err("area %s %s vnums %d-%d are backwards", area.Name, kind, r[0], r[1])
 		}
-	}
-	for i := range area.Mobs {
-		spawn := area.Mobs[i]
-		mob := db.GetMob(spawn.Mob) // grabs the mob template
-		if spawn.entity != nil {    // checks to see if we have a managed entity
-			if spawn.entity.GetCharData().State == ENTITY_STATE_DEAD { // is it dead?
-				//log.Printf("Removing dead entity %s\n", spawn.entity.GetCharData().Name)
-				//db.RemoveEntity(spawn.entity)
-				spawn.entity = nil // nil it out so we create a new one...
-			} else {
+		for _, other := range areas {
+			if other == area || other.Name == area.Name {
 				continue
 			}
+			if o := other.GetVnums(kind); vnum_ranges_overlap(r, o) {
+				return Err("area %s %s vnums %d-%d overlap %s's %d-%d", area.Name, kind, r[0], r[1], other.Name, o[0], o[1])
+			}
 		}
-		if spawn.entity == nil {
-			//log.Printf("Nil entity for spawn, spawning %s\n", mob.GetCharData().Name)
-			spawn.entity = db.SpawnEntity(mob)
-			spawn.entity.GetCharData().Room = spawn.Room
-			for _, e := range db.GetEntitiesInRoom(spawn.entity.GetCharData().Room, spawn.entity.GetCharData().Ship) {
-				if e == nil {
-					continue
-				}
-				if e.GetCharData().Id != spawn.entity.GetCharData().Id {
-					spawn.entity.GetCharData().AI.OnGreet(e)
-				}
+	}
+	if r := area.GetVnums(VNUM_ROOM); r != nil {
+		for _, room := range area.Rooms {
+			if !vnum_in_range(r, room.Id) {
+				return Err("area %s room %d is outside its room vnums %d-%d", area.Name, room.Id, r[0], r[1])
 			}
 		}
-		area.Mobs[i] = spawn
 	}
+	return nil
+}
+
+// room_copy_exit_flags copies the doors so the live room can open and close them without
+// changing the prototype.
+func room_copy_exit_flags(flags map[string]*RoomExitFlag) map[string]*RoomExitFlag {
+	ret := make(map[string]*RoomExitFlag)
+	for dir, f := range flags {
+		if f == nil {
+			continue
+		}
+		c := *f
+		c.found = false
+		ret[dir] = &c
+	}
+	return ret
+}
+
+// room_prototype_equal is true if the two rooms were built the same, ignoring what's in them.
+func room_prototype_equal(a *RoomData, b *RoomData) bool {
+	return a.Id == b.Id && a.Name == b.Name && a.Desc == b.Desc &&
+		room_prototype_field_equal(a.Exits, b.Exits) &&
+		room_prototype_field_equal(a.ExitFlags, b.ExitFlags) &&
+		room_prototype_field_equal(a.Flags, b.Flags) &&
+		room_prototype_field_equal(a.RoomProgs, b.RoomProgs) &&
+		room_prototype_field_equal(a.Extras, b.Extras) &&
+		room_prototype_field_equal(a.Coords, b.Coords) && a.Sector == b.Sector
+}
+
+// an empty map or slice is the same as a missing one, the builder commands make empty ones.
+func room_prototype_field_equal(a interface{}, b interface{}) bool {
+	if reflect.ValueOf(a).Len() == 0 && reflect.ValueOf(b).Len() == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// room_reset_area is the area that resets the room. Ship rooms are reset by the area the ship is
+// landed in, nil if it's in space.
+func room_reset_area(room *RoomData) *AreaData {
+	if room == nil {
+		return nil
+	}
+	if room.ship == 0 {
+		return room.Area
+	}
+	ship := DB().GetShip(room.ship)
+	if ship == nil || ship.GetData().InSpace {
+		return nil
+	}
+	if landed := DB().GetRoom(ship.GetData().LocationId, 0); landed != nil {
+		return landed.Area
+	}
+	return nil
+}
+
+// area_reset resets the area and schedules the next reset, until the area is removed or reloaded.
+func area_reset(area *AreaData) {
+	if area == nil || !DB().IsAreaLoaded(area) {
+		return
+	}
+	area.Reset()
 	ScheduleFunc(func() {
 		area_reset(area)
-	}, false, area.Reset)
+	}, false, area.ResetInterval)
 }
 
 func get_direction_string(direction string) string {
 	direction = strings.TrimSpace(strings.ToLower(direction))
+	if slice_contains_string(directions, direction) {
+		return direction
+	}
 	if strings.HasPrefix(direction, "ne") {
 		return "northeast"
 	}
@@ -534,5 +1473,46 @@ func room_is_landable(room *RoomData) bool {
 	if !room.HasFlag("spaceport") && !room.HasFlag("shipyard") && !room.HasFlag("hangar") {
 		return false
 	}
-	return true
+	return !room.IsFlagged(ROOM_FLAG_NO_SHIP)
+}
+
+// is_room_flag is true if flag is one of the ROOM_FLAG_* (or SHIP_ROOM_FLAGS_*) flags.
+func is_room_flag(flag string) bool {
+	return slice_contains_string(room_flag_list, flag)
+}
+
+// room_get_flags returns the room's flags along with the ones it has from its area.
+func room_get_flags(room *RoomData) []string {
+	flags := make([]string, 0)
+	flags = append(flags, room.Flags...)
+	if room.Area != nil {
+		for _, f := range room.Area.Flags {
+			if !slice_contains_string(flags, f) {
+				flags = append(flags, f)
+			}
+		}
+	}
+	return flags
+}
+
+// room_can_fight is true if the attacker is allowed to start (or keep up) a fight with the defender in the room.
+func room_can_fight(room *RoomData, attacker Entity, defender Entity) bool {
+	if room.IsFlagged(ROOM_FLAG_SAFE) {
+		return false
+	}
+	return !(room.IsFlagged(ROOM_FLAG_NO_PK) && attacker.IsPlayer() && defender.IsPlayer())
+}
+
+// room_is_full is true if the room is private and already has as many players as it fits.
+func room_is_full(room *RoomData) bool {
+	if !room.IsFlagged(ROOM_FLAG_PRIVATE) {
+		return false
+	}
+	players := 0
+	for _, e := range DB().GetEntitiesInRoom(room.Id, room.ship) {
+		if e.IsPlayer() {
+			players++
+		}
+	}
+	return players >= ROOM_PRIVATE_MAX
 }
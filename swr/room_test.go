@@ -0,0 +1,210 @@
+/*  Star Wars Role-Playing Mud
+ *  Copyright (C) 2022 @{See Authors}
+ *
+ *  This program is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  This program is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *  GNU General Public License for more details.
+ *
+ *  You should have received a copy of the GNU General Public License
+ *  along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+package swr
+
+import (
+	"sync"
+	"testing"
+)
+
+// test_database replaces the game database with an empty one in memory, without touching data/game.db.
+func test_database(rooms ...*RoomData) *GameDatabase {
+	_db = new(GameDatabase)
+	_db.m = &sync.Mutex{}
+	_db.clients = make([]Client, 0)
+	_db.entities = make([]Entity, 0)
+	_db.areas = make(map[string]*AreaData)
+	_db.rooms = make(map[uint]*RoomData)
+	_db.mobs = make(map[uint]*CharData)
+	_db.items = make(map[uint]*ItemData)
+	_db.ships = make([]Ship, 0)
+	_db.ship_prototypes = make(map[uint]*ShipData)
+	_db.starsystems = make([]Starsystem, 0)
+	_db.helps = make([]*HelpData, 0)
+	for _, r := range rooms {
+		_db.rooms[r.Id] = r
+	}
+	return _db
+}
+
+// test_door_rooms is two rooms joined north to south by a door on both sides.
+func test_door_rooms() (*RoomData, *RoomData) {
+	a := &RoomData{
+		Id:        1,
+		Name:      "Hangar",
+		Exits:     map[string]uint{"north": 2},
+		ExitFlags: map[string]*RoomExitFlag{"north": {Name: "blast door"}},
+	}
+	b := &RoomData{
+		Id:        2,
+		Name:      "Corridor",
+		Exits:     map[string]uint{"south": 1},
+		ExitFlags: map[string]*RoomExitFlag{"south": {Name: "blast door"}},
+	}
+	test_database(a, b)
+	return a, b
+}
+
+func TestRoomGetExit(t *testing.T) {
+	room := &RoomData{Id: 1, Exits: map[string]uint{"north": 2, "up": 3}}
+	test_database(room)
+	exit := room.GetExit("north")
+	if exit == nil {
+		t.Fatal("GetExit(north) = nil, want an exit")
+	}
+	if exit.GetDirection() != "north" {
+		t.Errorf("GetDirection() = %q, want north", exit.GetDirection())
+	}
+	if exit.GetRoom() != Room(room) {
+		t.Errorf("GetRoom() = %v, want %v", exit.GetRoom(), room)
+	}
+	if exit := room.GetExit("south"); exit != nil {
+		t.Errorf("GetExit(south) = %v, want nil", exit)
+	}
+}
+
+func TestRoomGetExitsOrder(t *testing.T) {
+	room := &RoomData{Id: 1, Exits: map[string]uint{
+		"down":   2,
+		"portal": 3,
+		"west":   4,
+		"north":  5,
+		"aft":    6,
+		"east":   7,
+	}}
+	test_database(room)
+	want := []string{"north", "east", "west", "down", "aft", "portal"}
+	exits := room.GetExits()
+	if len(exits) != len(want) {
+		t.Fatalf("GetExits() returned %d exits, want %d", len(exits), len(want))
+	}
+	for i, exit := range exits {
+		if exit.GetDirection() != want[i] {
+			t.Errorf("GetExits()[%d] = %s, want %s", i, exit.GetDirection(), want[i])
+		}
+	}
+	if exits := (&RoomData{Id: 8}).GetExits(); len(exits) != 0 {
+		t.Errorf("GetExits() on a room with no exits returned %d exits", len(exits))
+	}
+}
+
+func TestExitGetTarget(t *testing.T) {
+	a := &RoomData{Id: 1, Exits: map[string]uint{"north": 2, "east": 99}}
+	b := &RoomData{Id: 2, Exits: map[string]uint{"south": 1}}
+	test_database(a, b)
+	target := a.GetExit("north").GetTarget()
+	if target == nil || target.GetId() != 2 {
+		t.Errorf("north GetTarget() = %v, want room 2", target)
+	}
+	if target := a.GetExit("east").GetTarget(); target != nil {
+		t.Errorf("east GetTarget() = %v, want nil for a room that doesn't exist", target)
+	}
+	if target := b.GetExit("south").GetTarget(); target == nil || target.GetId() != 1 {
+		t.Errorf("south GetTarget() = %v, want room 1", target)
+	}
+}
+
+func TestExitDoorState(t *testing.T) {
+	room := &RoomData{
+		Id:    1,
+		Exits: map[string]uint{"north": 2, "south": 3, "east": 4},
+		ExitFlags: map[string]*RoomExitFlag{
+			"south": {},
+			"east":  {Closed: true, Locked: true, Key: 42, Name: "hatch"},
+		},
+	}
+	test_database(room)
+	tests := []struct {
+		direction string
+		door      bool
+		closed    bool
+		locked    bool
+		key       uint
+		name      string
+	}{
+		{"north", false, false, false, 0, ""},
+		{"south", true, false, false, 0, "door"},
+		{"east", true, true, true, 42, "hatch"},
+	}
+	for _, tt := range tests {
+		exit := room.GetExit(tt.direction)
+		if exit.IsDoor() != tt.door {
+			t.Errorf("%s IsDoor() = %v, want %v", tt.direction, exit.IsDoor(), tt.door)
+		}
+		if exit.IsClosed() != tt.closed {
+			t.Errorf("%s IsClosed() = %v, want %v", tt.direction, exit.IsClosed(), tt.closed)
+		}
+		if exit.IsLocked() != tt.locked {
+			t.Errorf("%s IsLocked() = %v, want %v", tt.direction, exit.IsLocked(), tt.locked)
+		}
+		if exit.GetKeyId() != tt.key {
+			t.Errorf("%s GetKeyId() = %d, want %d", tt.direction, exit.GetKeyId(), tt.key)
+		}
+		if exit.GetName() != tt.name {
+			t.Errorf("%s GetName() = %q, want %q", tt.direction, exit.GetName(), tt.name)
+		}
+	}
+}
+
+func TestRoomDoorTransitions(t *testing.T) {
+	a, b := test_door_rooms()
+	north := a.GetExit("north")
+	south := b.GetExit("south")
+	if north.IsClosed() || south.IsClosed() {
+		t.Fatal("the door should start open")
+	}
+
+	a.CloseDoor(nil, "north", true)
+	if !north.IsClosed() || !south.IsClosed() {
+		t.Errorf("after closing, north closed = %v, south closed = %v, want both closed", north.IsClosed(), south.IsClosed())
+	}
+	if north.IsLocked() || south.IsLocked() {
+		t.Error("closing a door without a key shouldn't lock it")
+	}
+
+	a.LockDoor(nil, "north", &ItemData{Id: 42, Name: "a keycard"})
+	if !north.IsLocked() {
+		t.Error("north should be locked after LockDoor")
+	}
+	if north.GetKeyId() != 42 {
+		t.Errorf("north GetKeyId() = %d, want 42", north.GetKeyId())
+	}
+
+	a.OpenDoor(nil, "north", true)
+	if north.IsClosed() || north.IsLocked() {
+		t.Errorf("after opening, north closed = %v, locked = %v, want open and unlocked", north.IsClosed(), north.IsLocked())
+	}
+	if south.IsClosed() {
+		t.Error("opening north should open the other side too")
+	}
+
+	b.CloseDoor(nil, "south", true)
+	if !north.IsClosed() || !north.IsLocked() {
+		t.Errorf("closing south should close and lock the keyed north side, closed = %v, locked = %v", north.IsClosed(), north.IsLocked())
+	}
+	if south.IsLocked() {
+		t.Error("closing south shouldn't lock south, it has no key")
+	}
+
+	b.OpenDoor(nil, "south", true)
+	b.ExitFlags["south"].Key = 42
+	a.CloseDoor(nil, "north", true)
+	if !south.IsLocked() {
+		t.Error("closing north should lock the keyed south side")
+	}
+}
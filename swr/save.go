@@ -0,0 +1,107 @@
+/*  Star Wars Role-Playing Mud
+ *  Copyright (C) 2022 @{See Authors}
+ *
+ *  This program is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  This program is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *  GNU General Public License for more details.
+ *
+ *  You should have received a copy of the GNU General Public License
+ *  along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+package swr
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// write_yaml marshals v to YAML and writes it to filename with [write_file_atomic].
+func write_yaml(filename string, v interface{}) error {
+	buf, err := yaml.Marshal(v)
+	if err != nil {
+		return Err("unable to marshal %s: %v", filename, err)
+	}
+	return write_file_atomic(filename, buf)
+}
+
+// write_file_atomic writes data to filename so that a crash (or a full disk) mid-write never
+// leaves a half written file behind. The data goes to a temp file in the same directory, is synced
+// to disk, then renamed over the original. The previous version is kept as filename.bak.
+func write_file_atomic(filename string, data []byte) error {
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(filename)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmp_name := tmp.Name()
+	// if anything goes wrong, the temp file goes away and the original is left alone.
+	fail := func(err error) error {
+		tmp.Close()
+		os.Remove(tmp_name)
+		return Err("unable to write %s: %v", filename, err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		return fail(err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fail(err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fail(err)
+	}
+	if err := os.Chmod(tmp_name, 0644); err != nil {
+		return fail(err)
+	}
+	if err := backup_file(filename); err != nil {
+		return fail(err)
+	}
+	if err := os.Rename(tmp_name, filename); err != nil {
+		return fail(err)
+	}
+	// sync the directory so the rename itself survives a crash.
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// backup_file keeps a copy of the current version of filename as filename.bak, replacing the last one.
+func backup_file(filename string) error {
+	bak := filename + ".bak"
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		return nil
+	}
+	os.Remove(bak)
+	// a hard link is free, fall back to a copy where links aren't supported.
+	if err := os.Link(filename, bak); err == nil {
+		return nil
+	}
+	src, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(bak, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
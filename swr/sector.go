@@ -0,0 +1,190 @@
+/*  Star Wars Role-Playing Mud
+ *  Copyright (C) 2022 @{See Authors}
+ *
+ *  This program is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  This program is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *  GNU General Public License for more details.
+ *
+ *  You should have received a copy of the GNU General Public License
+ *  along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+package swr
+
+import (
+	"log"
+	"strings"
+)
+
+// Room sectors, the lay of the land. A room without one is SECTOR_INSIDE unless its area says otherwise.
+const (
+	SECTOR_INSIDE     = "inside"
+	SECTOR_CITY       = "city"
+	SECTOR_FIELD      = "field"
+	SECTOR_FOREST     = "forest"
+	SECTOR_HILLS      = "hills"
+	SECTOR_MOUNTAIN   = "mountain"
+	SECTOR_DESERT     = "desert"
+	SECTOR_SWAMP      = "swamp"
+	SECTOR_WATER      = "water"
+	SECTOR_UNDERWATER = "underwater"
+	SECTOR_ICE        = "ice"
+	SECTOR_LAVA       = "lava"
+	SECTOR_VACUUM     = "vacuum"
+)
+
+// What an item protects its wearer from, see [ItemData.Protects].
+const (
+	PROTECT_BREATH = "breath" // breath masks, for vacuum and underwater.
+	PROTECT_SWIM   = "swim"   // flotation gear, for deep water.
+	PROTECT_HEAT   = "heat"   // heat suits, for lava fields.
+	PROTECT_COLD   = "cold"   // cold weather gear, for ice fields.
+)
+
+var protect_list = []string{PROTECT_BREATH, PROTECT_SWIM, PROTECT_HEAT, PROTECT_COLD}
+
+// SectorData is how a sector treats the people in it.
+type SectorData struct {
+	Name   string
+	Mv     int    // Mv it costs to move into the room.
+	Needs  string // PROTECT_* needed to go in at all, empty if anyone can.
+	Refuse string // what you're told when you can't go in.
+	Hazard string // PROTECT_* that keeps the environment from hurting you, empty if it's harmless.
+	Damage string // dice of damage the environment does every ENVIRONMENT_TICK seconds.
+	Hurt   string // what you're told when it hurts you.
+}
+
+var sector_list = []*SectorData{
+	{Name: SECTOR_INSIDE, Mv: 1},
+	{Name: SECTOR_CITY, Mv: 1},
+	{Name: SECTOR_FIELD, Mv: 2},
+	{Name: SECTOR_FOREST, Mv: 3},
+	{Name: SECTOR_HILLS, Mv: 3},
+	{Name: SECTOR_MOUNTAIN, Mv: 5},
+	{Name: SECTOR_DESERT, Mv: 3},
+	{Name: SECTOR_SWAMP, Mv: 4},
+	{Name: SECTOR_WATER, Mv: 4, Needs: PROTECT_SWIM,
+		Refuse: "The water's too deep, you'd need something to keep you afloat."},
+	{Name: SECTOR_UNDERWATER, Mv: 5, Needs: PROTECT_BREATH,
+		Refuse: "You'd drown down there without a breath mask.",
+		Hazard: PROTECT_BREATH, Damage: "2d6", Hurt: "&RYour lungs burn as you struggle for air!&d"},
+	{Name: SECTOR_ICE, Mv: 3,
+		Hazard: PROTECT_COLD, Damage: "1d6", Hurt: "&CThe bitter cold bites into you.&d"},
+	{Name: SECTOR_LAVA, Mv: 3,
+		Hazard: PROTECT_HEAT, Damage: "2d6", Hurt: "&RThe heat from the lava sears your skin!&d"},
+	{Name: SECTOR_VACUUM, Mv: 2, Needs: PROTECT_BREATH,
+		Refuse: "You can't breathe out there without a breath mask.",
+		Hazard: PROTECT_BREATH, Damage: "3d6", Hurt: "&RYou gasp for air in the vacuum!&d"},
+}
+
+// races that can go without protection, droids don't breathe and some races are at home in the water.
+var race_protects = map[string][]string{
+	"Mon Calamari": {PROTECT_BREATH, PROTECT_SWIM},
+	"Quarren":      {PROTECT_BREATH, PROTECT_SWIM},
+	"Gungan":       {PROTECT_BREATH, PROTECT_SWIM},
+	"Wookiee":      {PROTECT_COLD},
+	"Taun Taun":    {PROTECT_COLD},
+}
+
+// ENVIRONMENT_TICK is how many seconds apart harsh environments hurt the players in them.
+const ENVIRONMENT_TICK = 10
+
+// is_sector is true if s is one of the SECTOR_* names.
+func is_sector(s string) bool {
+	return get_sector(s) != nil
+}
+
+// get_sector returns the sector named s, nil if there isn't one.
+func get_sector(s string) *SectorData {
+	for _, sector := range sector_list {
+		if sector.Name == s {
+			return sector
+		}
+	}
+	return nil
+}
+
+// is_protect is true if s is one of the PROTECT_* names.
+func is_protect(s string) bool {
+	return slice_contains_string(protect_list, s)
+}
+
+// room_get_sector returns the room's sector, its area's if it doesn't have one, and SECTOR_INSIDE if neither do.
+func room_get_sector(room *RoomData) *SectorData {
+	if s := get_sector(room.Sector); s != nil {
+		return s
+	}
+	if room.Area != nil {
+		if s := get_sector(room.Area.Sector); s != nil {
+			return s
+		}
+	}
+	return sector_list[0]
+}
+
+// entity_is_protected is true if the entity is wearing something that protects it, or is born to it.
+func entity_is_protected(ch *CharData, protect string) bool {
+	if protect == "" {
+		return true
+	}
+	if strings.Contains(ch.Race, "Droid") && protect == PROTECT_BREATH {
+		return true
+	}
+	if slice_contains_string(race_protects[ch.Race], protect) {
+		return true
+	}
+	for _, item := range ch.Equipment {
+		if item != nil && slice_contains_string(item.Protects, protect) {
+			return true
+		}
+	}
+	return false
+}
+
+// entity_can_enter is false if the room's sector needs gear the entity doesn't have. Immortals go where they please.
+func entity_can_enter(entity Entity, room *RoomData) bool {
+	sector := room_get_sector(room)
+	if sector.Needs == "" || entity_is_immortal(entity) {
+		return true
+	}
+	return entity_is_protected(entity.GetCharData(), sector.Needs)
+}
+
+// entity_is_immortal is true for the players with the privileges to build and administer the game.
+func entity_is_immortal(entity Entity) bool {
+	if player, ok := entity.(*PlayerProfile); ok {
+		return player.Priv >= 100
+	}
+	return false
+}
+
+// processEnvironment hurts the players in harsh sectors who aren't protected from them.
+func processEnvironment() {
+	for _, e := range DB().GetPlayerEntities() {
+		ch := e.GetCharData()
+		if ch.State == ENTITY_STATE_DEAD {
+			continue
+		}
+		room := DB().GetRoom(ch.Room, ch.Ship)
+		if room == nil {
+			continue
+		}
+		sector := room_get_sector(room)
+		if sector.Hazard == "" || entity_is_immortal(e) || entity_is_protected(ch, sector.Hazard) {
+			continue
+		}
+		e.Send("\r\n%s\r\n", sector.Hurt)
+		e.ApplyDamage(uint(roll_dice(sector.Damage)))
+		if ch.State == ENTITY_STATE_DEAD {
+			room.SendToOthers(e, sprintf("\r\n&R%s succumbs to the elements.&d\r\n", ch.Name))
+			log.Printf("Entity %s [%d] has been killed by the %s.", ch.Name, ch.Id, sector.Name)
+			make_corpse(e)
+		}
+	}
+}
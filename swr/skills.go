@@ -41,10 +41,12 @@ var skill_list []string = []string{
 	"hunting",
 	"hyperdrives",
 	"lightsabers",
+	"lockpicking",
 	"lore",
 	"martial-arts",
 	"mines",
 	"missiles",
+	"perception",
 	"piloting",
 	"production",
 	"rifles",
@@ -64,3 +66,19 @@ func is_skill(s string) bool {
 	}
 	return false
 }
+
+// entity_skill_check rolls entity's skill against difficulty, true if they pulled it off.
+// Nobody always fails or always succeeds, and every so often they learn something.
+func entity_skill_check(entity Entity, skill string, difficulty int) bool {
+	chance := 50 + entity_get_skill_value(entity.GetCharData(), skill) - difficulty
+	if chance < 5 {
+		chance = 5
+	}
+	if chance > 95 {
+		chance = 95
+	}
+	if roll_dice("1d10") == 10 {
+		entity_add_skill_value(entity, skill, 1)
+	}
+	return roll_dice("1d100") <= chance
+}
@@ -0,0 +1,236 @@
+/*  Star Wars Role-Playing Mud
+ *  Copyright (C) 2022 @{See Authors}
+ *
+ *  This program is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  This program is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *  GNU General Public License for more details.
+ *
+ *  You should have received a copy of the GNU General Public License
+ *  along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+package swr
+
+import (
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+var Socials []*Social = make([]*Social, 0)
+var socials_mu sync.RWMutex
+
+// Social is a canned emote loaded from data/sys/socials.yml.
+//
+// Messages may use the following tokens:
+//
+//	$n - the actor's name        $N - the victim's name
+//	$e - actor's he/she/it       $E - victim's he/she/it
+//	$m - actor's him/her/it      $M - victim's him/her/it
+//	$s - actor's his/her/its     $S - victim's his/her/its
+type Social struct {
+	Name         string `yaml:"name"`
+	NoTarget     string `yaml:"no_target"`      // to the actor when used without a target.
+	NoTargetRoom string `yaml:"no_target_room"` // to the room when used without a target.
+	Self         string `yaml:"self"`           // to the actor when targeting themselves.
+	SelfRoom     string `yaml:"self_room"`      // to the room when the actor targets themselves.
+	TargetActor  string `yaml:"target_actor"`   // to the actor when used on someone.
+	TargetVictim string `yaml:"target_victim"`  // to the victim.
+	TargetRoom   string `yaml:"target_room"`    // to everyone else in the room.
+}
+
+func SocialsLoad() {
+	log.Printf("Loading socials list.")
+	socials, err := socials_read("data/sys/socials.yml")
+	ErrorCheck(err)
+	if err != nil {
+		return
+	}
+	socials_swap(socials)
+	log.Printf("%d socials successfully loaded.", len(socials))
+}
+
+// socials_swap replaces the socials list in one go so lookups never see a half loaded list.
+func socials_swap(socials []*Social) {
+	socials_mu.Lock()
+	defer socials_mu.Unlock()
+	Socials = socials
+}
+
+// socials_list returns the current socials list.
+func socials_list() []*Social {
+	socials_mu.RLock()
+	defer socials_mu.RUnlock()
+	return Socials
+}
+
+func socials_read(path string) ([]*Social, error) {
+	fp, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	socials := make([]*Social, 0)
+	err = yaml.Unmarshal(fp, &socials)
+	if err != nil {
+		return nil, err
+	}
+	return socials, nil
+}
+
+// social_find returns the social with the given name, or the first one it's a prefix of.
+func social_find(name string) *Social {
+	name = strings.ToLower(name)
+	if name == "" {
+		return nil
+	}
+	socials := socials_list()
+	for _, s := range socials {
+		if s.Name == name {
+			return s
+		}
+	}
+	for _, s := range socials {
+		if strings.HasPrefix(s.Name, name) {
+			return s
+		}
+	}
+	return nil
+}
+
+// social_pronoun returns the he/him/his style pronoun for a gender code (m/f/n).
+// kind is one of 'e' (subject), 'm' (object), or 's' (possessive).
+func social_pronoun(gender string, kind byte) string {
+	g := "n"
+	if len(gender) > 0 {
+		g = strings.ToLower(gender[0:1])
+	}
+	switch kind {
+	case 'e':
+		switch g {
+		case "m":
+			return "he"
+		case "f":
+			return "she"
+		}
+		return "it"
+	case 'm':
+		switch g {
+		case "m":
+			return "him"
+		case "f":
+			return "her"
+		}
+		return "it"
+	case 's':
+		switch g {
+		case "m":
+			return "his"
+		case "f":
+			return "her"
+		}
+		return "its"
+	}
+	return ""
+}
+
+// social_format replaces the $ tokens in a social message for the actor and victim (which may be nil).
+func social_format(msg string, actor Entity, victim Entity) string {
+	ch := actor.GetCharData()
+	vch := ch
+	if victim != nil {
+		vch = victim.GetCharData()
+	}
+	r := strings.NewReplacer(
+		"$n", ch.Name,
+		"$N", vch.Name,
+		"$e", social_pronoun(ch.Gender, 'e'),
+		"$E", social_pronoun(vch.Gender, 'e'),
+		"$m", social_pronoun(ch.Gender, 'm'),
+		"$M", social_pronoun(vch.Gender, 'm'),
+		"$s", social_pronoun(ch.Gender, 's'),
+		"$S", social_pronoun(vch.Gender, 's'),
+	)
+	return r.Replace(msg)
+}
+
+// do_social performs a social for the entity, args[0] being the (optional) target.
+func do_social(entity Entity, social *Social, args ...string) {
+	room := entity.GetRoom()
+	if len(args) == 0 || args[0] == "" {
+		if social.NoTarget != "" {
+			entity.Send("\r\n&d%s&d\r\n", social_format(social.NoTarget, entity, nil))
+		}
+		if social.NoTargetRoom != "" {
+			room.SendToOthers(entity, sprintf("\r\n&d%s&d\r\n", social_format(social.NoTargetRoom, entity, nil)))
+		}
+		return
+	}
+	victim := target_entity(entity, room.GetEntities(), args[0])
+	if victim == nil {
+		entity.Send("\r\n&dThey aren't here.\r\n")
+		return
+	}
+	if victim == entity {
+		if social.Self != "" {
+			entity.Send("\r\n&d%s&d\r\n", social_format(social.Self, entity, entity))
+		}
+		if social.SelfRoom != "" {
+			room.SendToOthers(entity, sprintf("\r\n&d%s&d\r\n", social_format(social.SelfRoom, entity, entity)))
+		}
+		return
+	}
+	if social.TargetActor != "" {
+		entity.Send("\r\n&d%s&d\r\n", social_format(social.TargetActor, entity, victim))
+	}
+	if social.TargetVictim != "" {
+		victim.Send("\r\n&d%s&d\r\n", social_format(social.TargetVictim, entity, victim))
+	}
+	if social.TargetRoom != "" {
+		msg := sprintf("\r\n&d%s&d\r\n", social_format(social.TargetRoom, entity, victim))
+		for _, e := range room.GetEntities() {
+			if e == nil || e == entity || e == victim {
+				continue
+			}
+			e.Send(msg)
+		}
+	}
+	if !victim.IsPlayer() {
+		if victim.GetCharData().AI != nil {
+			victim.GetCharData().AI.OnSocial(entity, social.Name)
+		}
+	}
+}
+
+func do_socials(entity Entity, args ...string) {
+	if entity == nil {
+		return
+	}
+	if !entity.IsPlayer() {
+		return
+	}
+	entity.Send("\r\n%s\r\n", MakeTitle("Socials", ANSI_TITLE_STYLE_NORMAL, ANSI_TITLE_ALIGNMENT_CENTER))
+	s := make([]string, 0)
+	for _, social := range socials_list() {
+		s = append(s, social.Name)
+	}
+	sort.Strings(s)
+	buf := ""
+	for idx, name := range s {
+		buf += sprintf(" &W%-16s&g |&d", name)
+		if (idx+1)%4 == 0 {
+			buf += "\r\n"
+		}
+	}
+	entity.Send(buf)
+	entity.Send("&d\r\n")
+}
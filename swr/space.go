@@ -45,6 +45,15 @@ type OribitalObject struct {
 	Radius     uint      `yaml:"radius"`                    // radius of orbital object in 1,000km
 	Position   []float32 `yaml:"position,flow"`             // position within the star system of orbital object
 	Spaceports []uint16  `yaml:"spaceports,flow,omitempty"` // spaceports is a list of roomId's one can land a ship at, len(0) and it's not landable.
+	Market     *Market   `yaml:"market,omitempty"`          // what the orbital trades, nil if it doesn't.
+	Day        uint      `yaml:"day,omitempty"`             // hours in the local day, PLANET_DAY if 0.
+	Climate    string    `yaml:"climate,omitempty"`         // the weather, see climate_list. The same as the type if empty.
+}
+
+// Market is what an orbital buys and sells.
+type Market struct {
+	Imports []string `yaml:"imports,flow"`
+	Exports []string `yaml:"exports,flow"`
 }
 
 const (
@@ -84,7 +93,7 @@ type ShipData struct {
 	Type             string             `yaml:"type"`                    // class of the ship
 	Value            uint               `yaml:"value"`                   // the cost of the ship
 	LocationId       uint               `yaml:"locationId"`              // roomId where we are docked or where we took off from if in space.
-	InSpace          bool               `yaml:"-"`                       // returns true if the ship is currently in space
+	InSpace          bool               `yaml:"inSpace,omitempty"`       // returns true if the ship is currently in space
 	CurrentSystem    string             `yaml:"currentSystem,omitempty"` // name of the current system its in
 	ShipyardId       uint               `yaml:"shipyardId"`              // where the ship came from.
 	Permission       uint               `yaml:"permission"`              // 0 - owner, 1 - group, 2 - guild/clan, 3 - faction, 4 - public
@@ -113,6 +122,25 @@ type ShipData struct {
 	HyperTimeUntil   uint               `yaml:"-"`                       // time in seconds until we exit hyperspace.
 	Hp               []uint             `yaml:"hp,flow"`                 // ship hitpoints as an array of uint's. [0] is current hp, [1] is max hp. Always a len() of 2.
 	Sp               []uint             `yaml:"sp,flow"`                 // ship shield points as an array of uint's. [0] is current sp, [1] is max sp. Always a len() of 2.
+
+	// items left in the ship's rooms by room id, only filled in for saving and loading.
+	Cargo map[uint][]WorldItem `yaml:"items,omitempty"`
+	// the jump the ship is in the middle of, only filled in for saving and loading.
+	Hyper *ShipHyperState `yaml:"hyper,omitempty"`
+
+	arrival  []float32 // where in HyperDestination the ship comes out of hyperspace.
+	duration uint      // how long the jump takes, to work out where the ship is along the way.
+	misjump  bool      // the nav computer got the course wrong and nobody knows it yet.
+}
+
+// ShipHyperState is a jump in progress, saved with the ship so a reboot doesn't strand it in hyperspace.
+type ShipHyperState struct {
+	Destination string    `yaml:"destination"`      // name of the star system the ship is headed for.
+	Origin      string    `yaml:"origin,omitempty"` // name of the star system the ship jumped from.
+	TimeUntil   uint      `yaml:"timeUntil"`        // seconds left until the ship arrives.
+	Duration    uint      `yaml:"duration"`         // how long the whole jump takes.
+	Arrival     []float32 `yaml:"arrival,flow"`     // where in the destination the ship comes out.
+	Misjump     bool      `yaml:"misjump,omitempty"`
 }
 
 type Ship interface {
@@ -123,19 +151,25 @@ func (s *ShipData) GetData() *ShipData {
 	return s
 }
 
+// JumpHyperspace sends the ship into hyperspace for target, coming out at pos in the target system.
 func (s *ShipData) JumpHyperspace(target Starsystem, pos []float32) {
 	db := DB()
 	s.HyperDestination = target
+	s.HyperOrigin = nil
 
 	for _, star := range db.starsystems {
 		if star.GetData().Name == s.CurrentSystem {
 			s.HyperOrigin = star
 		}
 	}
-	p1 := s.HyperOrigin.GetData().Position
-	p2 := target.GetData().Position
-	distance := distance_between_points(p1, p2)
-	s.HyperTimeUntil = uint(math.Round(float64(distance)))
+	if s.HyperOrigin == nil {
+		s.HyperOrigin = target
+	}
+	_, seconds := hyperdrive_time_calculation(s.HyperOrigin, target)
+	s.HyperTimeUntil = uint(seconds)
+	s.duration = s.HyperTimeUntil
+	s.arrival = pos
+	s.InHyper = true
 	s.Heading = float32(rand_min_max(0, 360))
 }
 
@@ -157,16 +191,20 @@ func ship_clone(ship Ship) *ShipData {
 		s.Crafter = sp.Crafter
 		s.Rooms = make(map[uint]*RoomData)
 		for i, r := range sp.Rooms {
-			s.Rooms[i] = r
+			s.Rooms[i] = ship_room_clone(r, s.Id)
 		}
 		s.Modules = make(map[string]uint)
 		for i, m := range sp.Modules {
 			s.Modules[i] = m
 		}
 		s.HighSlots = make([]*ItemData, 0)
-		s.HighSlots = append(s.HighSlots, sp.HighSlots...)
+		for _, i := range sp.HighSlots {
+			s.HighSlots = append(s.HighSlots, item_clone(i).GetData())
+		}
 		s.LowSlots = make([]*ItemData, 0)
-		s.LowSlots = append(s.LowSlots, sp.LowSlots...)
+		for _, i := range sp.LowSlots {
+			s.LowSlots = append(s.LowSlots, item_clone(i).GetData())
+		}
 		s.Blueprint = sp.Blueprint
 		s.Ramp = sp.Ramp
 		s.Cockpit = sp.Cockpit
@@ -176,19 +214,60 @@ func ship_clone(ship Ship) *ShipData {
 		s.CoPilot = sp.CoPilot
 		s.Target = sp.Target
 		s.Position = make([]float32, 2)
-		s.Position[0] = sp.Position[0]
-		s.Position[1] = sp.Position[1]
+		copy(s.Position, sp.Position)
 		s.Heading = sp.Heading
 		s.Speed = sp.Speed
 		s.MaxSpeed = sp.MaxSpeed
 		s.HyperOrigin = nil
 		s.HyperDestination = nil
 		s.HyperTimeUntil = 0
+		s.Hp = make([]uint, 2)
+		copy(s.Hp, sp.Hp)
+		s.Sp = make([]uint, 2)
+		copy(s.Sp, sp.Sp)
 		return s
 	}
 	return sp
 }
 
+// ship_room_clone copies a prototype's room for the ship shipId, every ship has its own
+// rooms so the items and doors in one X-Wing's cockpit aren't in all of them.
+func ship_room_clone(room *RoomData, shipId uint) *RoomData {
+	r := new(RoomData)
+	ErrorCheck(yaml_copy(room, r))
+	r.ship = shipId
+	r.Items = make([]Item, 0)
+	return r
+}
+
+// ship_load_rooms gives a ship loaded from disk its rooms back, with the ship's id and the
+// items that were left in them when it was saved.
+func ship_load_rooms(d *GameDatabase, ship *ShipData) {
+	for id, r := range ship.Rooms {
+		r.ship = ship.Id
+		r.Items = make([]Item, 0)
+		for _, w := range ship.Cargo[id] {
+			if w.Item != nil {
+				r.AddItem(world_item_restore(d, w))
+			}
+		}
+	}
+	ship.Cargo = nil
+}
+
+// ship_room_items is what's lying around in each of the ship's rooms, for saving.
+func ship_room_items(ship *ShipData) map[uint][]WorldItem {
+	ret := make(map[uint][]WorldItem)
+	for id, r := range ship.Rooms {
+		for _, i := range r.Items {
+			if i != nil {
+				ret[id] = append(ret[id], world_item_state(i))
+			}
+		}
+	}
+	return ret
+}
+
 type Starsystem interface {
 	GetData() *StarSystemData
 }
@@ -265,21 +344,23 @@ func do_leave_ship(entity Entity, args ...string) {
 		return
 	} else {
 		to_room := DB().GetRoom(ship.GetData().LocationId, 0)
+		entity_stop_piloting(entity)
 		ch := entity.GetCharData()
 		ch.Room = to_room.Id
 		ch.Ship = 0
 		room.SendToOthers(entity, sprintf("\r\n%s has left the ship.\r\n", ch.Name))
 		to_room.SendToOthers(entity, sprintf("\r\n%s has arrived.\r\n", ch.Name))
-		entity.Send("\r\nYou leave the ship.")
-		ServerQueue <- MudClientCommand{
-			Entity:  entity,
-			Command: "look",
-		}
+		entity.Send("\r\nYou leave the ship.\r\n")
+		do_look(entity)
 	}
 }
 
-//lint:ignore U1000 useful calculation
+// hyperdrive_time_calculation is how long a jump from origin to destination takes.
 func hyperdrive_time_calculation(origin Starsystem, destination Starsystem) (time.Duration, int) {
 	distance := distance_between_points(origin.GetData().Position, destination.GetData().Position)
-	return time.Duration(distance) * time.Second, int(math.Round(float64(distance)))
+	seconds := int(math.Round(float64(distance / HYPERSPACE_SPEED)))
+	if seconds < HYPERSPACE_MIN_TIME {
+		seconds = HYPERSPACE_MIN_TIME
+	}
+	return time.Duration(seconds) * time.Second, seconds
 }
@@ -0,0 +1,222 @@
+/*  Star Wars Role-Playing Mud
+ *  Copyright (C) 2022 @{See Authors}
+ *
+ *  This program is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  This program is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *  GNU General Public License for more details.
+ *
+ *  You should have received a copy of the GNU General Public License
+ *  along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+package swr
+
+import "testing"
+
+// test_ship_prototype is a two room ship, a cockpit with a hatch down to the ramp.
+func test_ship_prototype() *ShipData {
+	return &ShipData{
+		Id:   2,
+		OId:  2,
+		Name: "X-Wing",
+		Type: "X-Wing",
+		Rooms: map[uint]*RoomData{
+			1: {
+				Id:        1,
+				Name:      "Cockpit",
+				Exits:     map[string]uint{"down": 2},
+				ExitFlags: map[string]*RoomExitFlag{"down": {Name: "hatch"}},
+				Flags:     []string{SHIP_ROOM_FLAGS_COCKPIT},
+			},
+			2: {
+				Id:        2,
+				Name:      "Ramp",
+				Exits:     map[string]uint{"up": 1},
+				ExitFlags: map[string]*RoomExitFlag{"up": {Name: "hatch"}},
+				Flags:     []string{SHIP_ROOM_FLAGS_RAMP},
+			},
+		},
+		Modules:  map[string]uint{SHIP_MODULE_ENGINE: 100},
+		Cockpit:  1,
+		Ramp:     2,
+		Position: []float32{0, 0},
+		Hp:       []uint{100, 100},
+		Sp:       []uint{50, 50},
+	}
+}
+
+// test_spawn_ships spawns two ships of the same type into a fresh database.
+func test_spawn_ships() (*ShipData, *ShipData, *ShipData) {
+	db := test_database()
+	proto := test_ship_prototype()
+	a := db.SpawnShip(proto).GetData()
+	b := db.SpawnShip(proto).GetData()
+	return proto, a, b
+}
+
+func TestShipRoomsHaveTheirShipId(t *testing.T) {
+	proto, a, b := test_spawn_ships()
+	for _, s := range []*ShipData{a, b} {
+		if len(s.Rooms) != len(proto.Rooms) {
+			t.Fatalf("ship %d has %d rooms, want %d", s.Id, len(s.Rooms), len(proto.Rooms))
+		}
+		for id, r := range s.Rooms {
+			if r.ship != s.Id {
+				t.Errorf("ship %d room %d belongs to ship %d", s.Id, id, r.ship)
+			}
+			if r == proto.Rooms[id] {
+				t.Errorf("ship %d room %d is the prototype's room", s.Id, id)
+			}
+			if got := DB().GetRoom(id, s.Id); got != r {
+				t.Errorf("GetRoom(%d, %d) = %v, want the ship's own room", id, s.Id, got)
+			}
+		}
+	}
+	if a.Rooms[1] == b.Rooms[1] {
+		t.Error("both ships share the same cockpit")
+	}
+}
+
+func TestShipItemsAreIndependent(t *testing.T) {
+	proto, a, b := test_spawn_ships()
+	a.Rooms[1].AddItem(&ItemData{Id: gen_item_id(), OId: 3, Name: "a small comlink"})
+	if len(a.Rooms[1].Items) != 1 {
+		t.Fatalf("ship A's cockpit has %d items, want 1", len(a.Rooms[1].Items))
+	}
+	if len(b.Rooms[1].Items) != 0 {
+		t.Errorf("ship B's cockpit has %d items, want 0", len(b.Rooms[1].Items))
+	}
+	if len(proto.Rooms[1].Items) != 0 {
+		t.Errorf("the prototype's cockpit has %d items, want 0", len(proto.Rooms[1].Items))
+	}
+}
+
+func TestShipDoorsAreIndependent(t *testing.T) {
+	proto, a, b := test_spawn_ships()
+	a.Rooms[1].CloseDoor(nil, "down", true)
+	if !a.Rooms[1].GetExit("down").IsClosed() || !a.Rooms[2].GetExit("up").IsClosed() {
+		t.Error("closing ship A's hatch should close both sides of it")
+	}
+	a.Rooms[1].LockDoor(nil, "down", &ItemData{Id: 42, Name: "a keycard"})
+	if !a.Rooms[1].GetExit("down").IsLocked() {
+		t.Error("ship A's hatch should be locked")
+	}
+	for _, s := range []*ShipData{b, proto} {
+		down := s.Rooms[1].GetExit("down")
+		up := s.Rooms[2].GetExit("up")
+		if down.IsClosed() || down.IsLocked() || up.IsClosed() {
+			t.Errorf("ship %d's hatch changed with ship A's, closed %v locked %v", s.Id, down.IsClosed(), down.IsLocked())
+		}
+		if down.GetKeyId() != 0 {
+			t.Errorf("ship %d's hatch has key %d, want none", s.Id, down.GetKeyId())
+		}
+	}
+}
+
+func TestShipRoomItemsRoundTrip(t *testing.T) {
+	_, a, _ := test_spawn_ships()
+	bag := &ItemData{Id: gen_item_id(), OId: 2, Name: "a small bag", Type: ITEM_TYPE_CONTAINER, Items: make([]Item, 0)}
+	bag.AddItem(&ItemData{Id: gen_item_id(), OId: 3, Name: "a small comlink"})
+	a.Rooms[1].AddItem(bag)
+	a.Rooms[2].AddItem(&ItemData{Id: gen_item_id(), OId: 5, Name: "a trash bin"})
+
+	// save and load the ship the same way the database does.
+	a.Cargo = ship_room_items(a)
+	loaded := new(ShipData)
+	if err := yaml_copy(a, loaded); err != nil {
+		t.Fatal(err)
+	}
+	a.Cargo = nil
+	ship_load_rooms(DB(), loaded)
+
+	if loaded.Cargo != nil {
+		t.Error("Cargo should be emptied once the items are back in the rooms")
+	}
+	for id, r := range loaded.Rooms {
+		if r.ship != loaded.Id {
+			t.Errorf("loaded room %d belongs to ship %d, want %d", id, r.ship, loaded.Id)
+		}
+	}
+	cockpit := loaded.Rooms[1].Items
+	if len(cockpit) != 1 || cockpit[0].GetData().Name != "a small bag" {
+		t.Fatalf("loaded cockpit has %v, want the bag", cockpit)
+	}
+	if cockpit[0].GetId() != bag.Id {
+		t.Errorf("the bag came back as %d, want %d", cockpit[0].GetId(), bag.Id)
+	}
+	contents := cockpit[0].GetData().Items
+	if len(contents) != 1 || contents[0].GetData().Name != "a small comlink" {
+		t.Errorf("the bag came back holding %v, want the comlink", contents)
+	}
+	ramp := loaded.Rooms[2].Items
+	if len(ramp) != 1 || ramp[0].GetData().Name != "a trash bin" {
+		t.Errorf("loaded ramp has %v, want the trash bin", ramp)
+	}
+}
+
+func TestShipSpawnsWaitWhileInSpace(t *testing.T) {
+	_, a, _ := test_spawn_ships()
+	DB().items[3] = &ItemData{Id: 3, OId: 3, Name: "a small comlink"}
+	spawn := &ItemSpawn{Item: 3, Room: 1, Ship: a.Id}
+
+	a.InSpace = true
+	if err := area_reset_item(spawn); err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Rooms[1].Items) != 0 {
+		t.Errorf("the cockpit has %d items while the ship is in space, want 0", len(a.Rooms[1].Items))
+	}
+
+	a.InSpace = false
+	if err := area_reset_item(spawn); err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Rooms[1].Items) != 1 {
+		t.Errorf("the cockpit has %d items once the ship has landed, want 1", len(a.Rooms[1].Items))
+	}
+}
+
+func TestShipHyperspaceRoundTrip(t *testing.T) {
+	_, a, _ := test_spawn_ships()
+	corellia := &StarSystemData{Name: "Corellia", Position: []float32{0, 0}}
+	tatooine := &StarSystemData{Name: "Tatooine", Position: []float32{500, 0}}
+	DB().starsystems = append(DB().starsystems, corellia, tatooine)
+	a.InSpace = true
+	a.CurrentSystem = "Corellia"
+	a.JumpHyperspace(tatooine, []float32{120, 40})
+	a.HyperTimeUntil -= 2
+
+	// save and load the ship the same way the database does.
+	a.Hyper = ship_hyper_state(a)
+	loaded := new(ShipData)
+	if err := yaml_copy(a, loaded); err != nil {
+		t.Fatal(err)
+	}
+	a.Hyper = nil
+	ship_load_hyper(loaded)
+
+	if loaded.Hyper != nil {
+		t.Error("Hyper should be emptied once the jump is restored")
+	}
+	if !loaded.InHyper {
+		t.Fatal("the loaded ship should still be in hyperspace")
+	}
+	if loaded.HyperDestination == nil || loaded.HyperDestination.GetData() != tatooine {
+		t.Errorf("the loaded ship is headed for %v, want Tatooine", loaded.HyperDestination)
+	}
+	if loaded.HyperOrigin == nil || loaded.HyperOrigin.GetData() != corellia {
+		t.Errorf("the loaded ship jumped from %v, want Corellia", loaded.HyperOrigin)
+	}
+	if loaded.HyperTimeUntil != a.HyperTimeUntil || loaded.duration != a.duration {
+		t.Errorf("the loaded ship has %d of %d seconds left, want %d of %d", loaded.HyperTimeUntil, loaded.duration, a.HyperTimeUntil, a.duration)
+	}
+	if len(loaded.arrival) != 2 || loaded.arrival[0] != 120 || loaded.arrival[1] != 40 {
+		t.Errorf("the loaded ship arrives at %v, want [120 40]", loaded.arrival)
+	}
+}
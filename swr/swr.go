@@ -47,8 +47,13 @@ func Main() {
 	defer DB().Save()
 	DB().ResetAll()
 	CommandsLoad()
+	SocialsLoad()
 	LanguageLoad()
+	WeatherLoad()
 	StartBackup()
+	ScheduleFunc(processEnvironment, true, ENVIRONMENT_TICK)
+	ScheduleFunc(calendar_tick, true, GAME_HOUR)
+	ScheduleFunc(processShips, true, SHIP_TICK)
 	log.Printf("Server took %s seconds to boot.", time.Since(startup).String())
 	ServerStart(Config().Addr)
 }
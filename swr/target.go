@@ -0,0 +1,159 @@
+/*  Star Wars Role-Playing Mud
+ *  Copyright (C) 2022 @{See Authors}
+ *
+ *  This program is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  This program is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *  GNU General Public License for more details.
+ *
+ *  You should have received a copy of the GNU General Public License
+ *  along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+package swr
+
+import (
+	"strconv"
+	"strings"
+)
+
+// TargetQuery is a parsed target argument as typed by a player. Targets can be a plain
+// keyword ("trooper"), numbered ("2.trooper"), every match ("all.blaster"), everything ("all"),
+// or the entity issuing the command ("me" / "self").
+type TargetQuery struct {
+	Keyword string // keyword prefix to match, empty matches everything.
+	Index   int    // 1 based index of the match we want. 2.trooper is the second trooper.
+	All     bool   // every match instead of just the Index'th one.
+	Self    bool   // me/self, the entity issuing the command.
+}
+
+// target_parse breaks a target argument into a [TargetQuery].
+func target_parse(arg string) TargetQuery {
+	q := TargetQuery{Index: 1}
+	arg = strings.ToLower(strings.TrimSpace(arg))
+	switch arg {
+	case "me", "self":
+		q.Self = true
+		return q
+	case "all":
+		q.All = true
+		return q
+	}
+	if strings.HasPrefix(arg, "all.") {
+		q.All = true
+		q.Keyword = strings.TrimPrefix(arg, "all.")
+		return q
+	}
+	if dot := strings.Index(arg, "."); dot > 0 {
+		if n, err := strconv.Atoi(arg[:dot]); err == nil && n > 0 {
+			q.Index = n
+			q.Keyword = arg[dot+1:]
+			return q
+		}
+	}
+	q.Keyword = arg
+	return q
+}
+
+// Does any of the keywords start with the query keyword? An empty keyword matches everything.
+func (q TargetQuery) matches(keywords []string) bool {
+	if q.Keyword == "" {
+		return true
+	}
+	for _, k := range keywords {
+		if strings.HasPrefix(strings.ToLower(k), q.Keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// Is the n'th match (1 based) one the query wants? All wants every match.
+func (q TargetQuery) wants(n int) bool {
+	return q.All || n == q.Index
+}
+
+// target_items returns the items in the list matching the target argument.
+// me/self never matches an item.
+func target_items(items []Item, arg string) []Item {
+	q := target_parse(arg)
+	matches := make([]Item, 0)
+	if q.Self {
+		return matches
+	}
+	n := 0
+	for _, i := range items {
+		if i == nil {
+			continue
+		}
+		if q.matches(i.GetKeywords()) {
+			n++
+			if q.wants(n) {
+				matches = append(matches, i)
+			}
+		}
+	}
+	return matches
+}
+
+// target_item returns the first item matching the target argument, nil if nothing matched.
+func target_item(items []Item, arg string) Item {
+	matches := target_items(items, arg)
+	if len(matches) == 0 {
+		return nil
+	}
+	return matches[0]
+}
+
+// target_entities returns the entities in the list matching the target argument.
+// The entity issuing the command is skipped unless it asked for me/self.
+func target_entities(entity Entity, entities []Entity, arg string) []Entity {
+	q := target_parse(arg)
+	matches := make([]Entity, 0)
+	if q.Self {
+		if entity != nil {
+			matches = append(matches, entity)
+		}
+		return matches
+	}
+	n := 0
+	for _, e := range entities {
+		if e == nil || e == entity {
+			continue
+		}
+		ch := e.GetCharData()
+		if q.matches(ch.Keywords) || q.matches(strings.Fields(ch.Name)) {
+			n++
+			if q.wants(n) {
+				matches = append(matches, e)
+			}
+		}
+	}
+	return matches
+}
+
+// target_entity returns the first entity matching the target argument, nil if nothing matched.
+func target_entity(entity Entity, entities []Entity, arg string) Entity {
+	matches := target_entities(entity, entities, arg)
+	if len(matches) == 0 {
+		return nil
+	}
+	return matches[0]
+}
+
+// Inventories are stored as []*ItemData, this converts them for use with the target functions.
+func target_inventory(ch *CharData) []Item {
+	ret := make([]Item, 0, len(ch.Inventory))
+	for _, i := range ch.Inventory {
+		if i == nil {
+			continue
+		}
+		ret = append(ret, i)
+	}
+	return ret
+}
@@ -108,6 +108,14 @@ func umin(min uint, value uint) uint {
 	}
 	return min
 }
+
+// int version of umax
+func max(max int, value int) int {
+	if value > max {
+		return value
+	}
+	return max
+}
 func umax(min uint, value uint) uint {
 	if value > min {
 		return value
@@ -195,6 +203,17 @@ func slice_contains_string(slice []string, value string) bool {
 	return false
 }
 
+// slice_remove_string returns the slice without value.
+func slice_remove_string(slice []string, value string) []string {
+	ret := make([]string, 0)
+	for _, s := range slice {
+		if !strings.EqualFold(s, value) {
+			ret = append(ret, s)
+		}
+	}
+	return ret
+}
+
 // direction_reverse takes a direction string and returns its spacial opposite direction.
 // ex: east -> west   north -> south   up -> down
 func direction_reverse(direction string) string {
@@ -0,0 +1,648 @@
+/*  Star Wars Role-Playing Mud
+ *  Copyright (C) 2022 @{See Authors}
+ *
+ *  This program is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  This program is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *  GNU General Public License for more details.
+ *
+ *  You should have received a copy of the GNU General Public License
+ *  along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+package swr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/robertkrimen/otto"
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationError is a problem found in the world data by [Validate].
+type ValidationError struct {
+	File string
+	Line int // 0 when the problem isn't on any one line.
+	Msg  string
+}
+
+func (v ValidationError) String() string {
+	if v.Line > 0 {
+		return sprintf("%s:%d: %s", v.File, v.Line, v.Msg)
+	}
+	return sprintf("%s: %s", v.File, v.Msg)
+}
+
+// where something was defined, so duplicates and dangling references can point back at it.
+type validate_loc struct {
+	file string
+	line int
+}
+
+func (l validate_loc) String() string {
+	return sprintf("%s:%d", l.file, l.line)
+}
+
+// validator loads the yaml under ./data and ./docs the same way the game does, without the
+// database or the network, and collects everything wrong with it.
+type validator struct {
+	errors []ValidationError
+	rooms  map[uint]validate_loc
+	mobs   map[uint]validate_loc
+	items  map[uint]validate_loc
+	// the items, for checking what resets do with them.
+	item_protos map[uint]*ItemData
+	areas       []*AreaData
+	// the yaml of each area, by file, for line numbers when checking references.
+	area_files   map[*AreaData]string
+	area_nodes   map[*AreaData]*yaml.Node
+	planets      []*StarSystemData
+	planet_files map[*StarSystemData]string
+	planet_nodes map[*StarSystemData]*yaml.Node
+}
+
+var validate_line_re = regexp.MustCompile(`^line (\d+): (.*)$`)
+var validate_dice_re = regexp.MustCompile(`^\d+d\d+(\+\d+)?$`)
+
+// Validate checks the world data for broken references (exits to rooms that don't exist, spawns
+// of mobs or items that don't exist, duplicate vnums...) and schema errors (unknown fields,
+// bad item types, mud progs that don't compile...). Every problem is printed as file:line: message.
+// Returns the number of problems found.
+func Validate() int {
+	v := &validator{
+		errors:       make([]ValidationError, 0),
+		rooms:        make(map[uint]validate_loc),
+		mobs:         make(map[uint]validate_loc),
+		items:        make(map[uint]validate_loc),
+		item_protos:  make(map[uint]*ItemData),
+		areas:        make([]*AreaData, 0),
+		area_files:   make(map[*AreaData]string),
+		area_nodes:   make(map[*AreaData]*yaml.Node),
+		planets:      make([]*StarSystemData, 0),
+		planet_files: make(map[*StarSystemData]string),
+		planet_nodes: make(map[*StarSystemData]*yaml.Node),
+	}
+	v.validate_config("data/sys/config.yml")
+	v.validate_commands("data/sys/commands.yml")
+	v.validate_socials("data/sys/socials.yml")
+	v.validate_files("data/items", v.validate_item)
+	v.validate_files("data/mobs", v.validate_mob)
+	v.validate_files("data/areas", v.validate_area)
+	v.validate_files("data/planets", v.validate_planet)
+	v.validate_files("data/ships", v.validate_ship)
+	v.validate_files("docs", v.validate_help)
+	v.validate_references()
+
+	sort.SliceStable(v.errors, func(i, j int) bool {
+		if v.errors[i].File != v.errors[j].File {
+			return v.errors[i].File < v.errors[j].File
+		}
+		return v.errors[i].Line < v.errors[j].Line
+	})
+	for _, e := range v.errors {
+		fmt.Println(e.String())
+	}
+	if len(v.errors) > 0 {
+		fmt.Printf("\n%d problems found.\n", len(v.errors))
+	} else {
+		fmt.Printf("World data is valid. %d areas, %d rooms, %d mobs, %d items.\n", len(v.areas), len(v.rooms), len(v.mobs), len(v.items))
+	}
+	return len(v.errors)
+}
+
+func (v *validator) error(file string, line int, format string, any ...interface{}) {
+	v.errors = append(v.errors, ValidationError{File: file, Line: line, Msg: sprintf(format, any...)})
+}
+
+// validate_files runs fn for every yaml file under dir.
+func (v *validator) validate_files(dir string, fn func(path string)) {
+	files, err := yaml_files(dir, "")
+	if err != nil {
+		v.error(dir, 0, "%v", err)
+		return
+	}
+	for _, path := range files {
+		fn(path)
+	}
+}
+
+// validate_read decodes the yaml file at path into out, rejecting fields out doesn't have.
+// Returns the parsed document for line numbers, or nil if the file couldn't be read at all.
+func (v *validator) validate_read(path string, out interface{}) *yaml.Node {
+	fp, err := os.ReadFile(path)
+	if err != nil {
+		v.error(path, 0, "%v", err)
+		return nil
+	}
+	doc := new(yaml.Node)
+	if err := yaml.Unmarshal(fp, doc); err != nil {
+		v.validate_yaml_error(path, err)
+		return nil
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(fp))
+	dec.KnownFields(true)
+	if err := dec.Decode(out); err != nil {
+		v.validate_yaml_error(path, err)
+		var terr *yaml.TypeError
+		if !errors.As(err, &terr) {
+			return nil
+		}
+		// type errors still decode everything else, carry on with what we've got.
+	}
+	return doc
+}
+
+// validate_yaml_error splits a yaml error into one problem per line.
+func (v *validator) validate_yaml_error(path string, err error) {
+	var terr *yaml.TypeError
+	msgs := []string{err.Error()}
+	if errors.As(err, &terr) {
+		msgs = terr.Errors
+	}
+	for _, msg := range msgs {
+		msg = strings.TrimPrefix(msg, "yaml: ")
+		if m := validate_line_re.FindStringSubmatch(msg); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			v.error(path, line, "%s", m[2])
+		} else {
+			v.error(path, 0, "%s", msg)
+		}
+	}
+}
+
+// node_get returns the value of key in a yaml mapping (or document), nil if it isn't there.
+func node_get(n *yaml.Node, key string) *yaml.Node {
+	if n == nil {
+		return nil
+	}
+	if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		n = n.Content[0]
+	}
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// node_key returns the key node of key in a yaml mapping, nil if it isn't there.
+func node_key(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i]
+		}
+	}
+	return nil
+}
+
+// node_index returns the i'th entry of a yaml sequence, nil if there isn't one.
+func node_index(n *yaml.Node, i int) *yaml.Node {
+	if n == nil || n.Kind != yaml.SequenceNode || i >= len(n.Content) {
+		return nil
+	}
+	return n.Content[i]
+}
+
+// node_line is the line of the first node that exists, 0 if none of them do.
+func node_line(nodes ...*yaml.Node) int {
+	for _, n := range nodes {
+		if n != nil {
+			return n.Line
+		}
+	}
+	return 0
+}
+
+func (v *validator) validate_prog(path string, line int, name string, src string) {
+	if _, err := otto.New().Compile("", src); err != nil {
+		v.error(path, line, "prog %s doesn't compile: %v", name, err)
+	}
+}
+
+func (v *validator) validate_config(path string) {
+	config := new(Configuration)
+	doc := v.validate_read(path, config)
+	if doc == nil {
+		return
+	}
+	if config.Addr == "" {
+		v.error(path, node_line(node_get(doc, "addr")), "addr is missing")
+	}
+	switch strings.ToLower(config.Database) {
+	case "", DATABASE_YAML, DATABASE_SQLITE:
+	default:
+		v.error(path, node_line(node_get(doc, "database")), "unknown database backend %s", config.Database)
+	}
+}
+
+func (v *validator) validate_commands(path string) {
+	fp, err := os.ReadFile(path)
+	if err != nil {
+		v.error(path, 0, "%v", err)
+		return
+	}
+	commands := make([]*Command, 0)
+	if err := yaml.Unmarshal(fp, &commands); err != nil {
+		v.validate_yaml_error(path, err)
+		return
+	}
+	for _, problem := range commands_validate(commands) {
+		v.error(path, 0, "%s", problem)
+	}
+}
+
+func (v *validator) validate_socials(path string) {
+	socials := make([]*Social, 0)
+	doc := v.validate_read(path, &socials)
+	if doc == nil {
+		return
+	}
+	seen := make(map[string]bool)
+	for i, s := range socials {
+		line := node_line(node_index(doc.Content[0], i))
+		if s.Name == "" {
+			v.error(path, line, "social has no name")
+		}
+		if seen[s.Name] {
+			v.error(path, line, "social %s is defined more than once", s.Name)
+		}
+		seen[s.Name] = true
+	}
+}
+
+// validate_item_data checks the parts of an item that are the same wherever it's defined.
+func (v *validator) validate_item_data(path string, n *yaml.Node, item *ItemData) {
+	line := node_line(n)
+	if item.Name == "" {
+		v.error(path, line, "item %d has no name", item.Id)
+	}
+	if !item_is_item_type(item.Type) {
+		v.error(path, node_line(node_get(n, "type"), n), "item %d has an unknown type %q", item.Id, item.Type)
+	}
+	if item.WearLoc != nil && !item_is_wearable_slot(*item.WearLoc) && *item.WearLoc != "weapon" {
+		v.error(path, node_line(node_get(n, "wearLoc"), n), "item %d has an unknown wear location %q", item.Id, *item.WearLoc)
+	}
+	// weapon types double as the skill used to wield them.
+	if item.WeaponType != nil && !item_is_weapon_type(*item.WeaponType) && !is_skill(*item.WeaponType) {
+		v.error(path, node_line(node_get(n, "weaponType"), n), "item %d has an unknown weapon type %q", item.Id, *item.WeaponType)
+	}
+	if item.Dmg != nil && !validate_dice_re.MatchString(strings.ToLower(*item.Dmg)) {
+		v.error(path, node_line(node_get(n, "dmgRoll"), n), "item %d has a bad damage roll %q, use something like 1d6+2", item.Id, *item.Dmg)
+	}
+	for _, p := range item.Protects {
+		if !is_protect(p) {
+			v.error(path, node_line(node_get(n, "protects"), n), "item %d protects from an unknown %q, use one of %s", item.Id, p, strings.Join(protect_list, ", "))
+		}
+	}
+}
+
+func (v *validator) validate_item(path string) {
+	item := new(ItemData)
+	doc := v.validate_read(path, item)
+	if doc == nil {
+		return
+	}
+	line := node_line(node_get(doc, "id"), doc)
+	if item.Id == 0 {
+		v.error(path, line, "item has no id")
+	} else if loc, ok := v.items[item.Id]; ok {
+		v.error(path, line, "item id %d is already used by %s", item.Id, loc)
+	} else {
+		v.items[item.Id] = validate_loc{path, line}
+		v.item_protos[item.Id] = item
+	}
+	v.validate_item_data(path, doc.Content[0], item)
+}
+
+func (v *validator) validate_mob(path string) {
+	mob := new(CharData)
+	doc := v.validate_read(path, mob)
+	if doc == nil {
+		return
+	}
+	line := node_line(node_get(doc, "id"), doc)
+	if mob.Id == 0 {
+		v.error(path, line, "mob has no id")
+	} else if loc, ok := v.mobs[mob.Id]; ok {
+		v.error(path, line, "mob id %d is already used by %s", mob.Id, loc)
+	} else {
+		v.mobs[mob.Id] = validate_loc{path, line}
+	}
+	if mob.Name == "" {
+		v.error(path, line, "mob %d has no name", mob.Id)
+	}
+	if mob.Race != "" && !slice_contains_string(race_list, mob.Race) {
+		v.error(path, node_line(node_get(doc, "race"), doc), "mob %d has an unknown race %q", mob.Id, mob.Race)
+	}
+	if mob.Gender != "" && !strings.Contains("mfn", strings.ToLower(mob.Gender[0:1])) {
+		v.error(path, node_line(node_get(doc, "gender"), doc), "mob %d has an unknown gender %q, use m, f or n", mob.Id, mob.Gender)
+	}
+	for name, n := range map[string][]int{"hp": mob.Hp, "mp": mob.Mp, "mv": mob.Mv} {
+		if len(n) != 2 {
+			v.error(path, node_line(node_get(doc, name), doc), "mob %d %s should be [current, max]", mob.Id, name)
+		}
+	}
+	if len(mob.Stats) != 6 {
+		v.error(path, node_line(node_get(doc, "stats"), doc), "mob %d stats should be [str, int, dex, wis, con, cha]", mob.Id)
+	}
+	for skill := range mob.Skills {
+		if !is_skill(skill) {
+			v.error(path, node_line(node_key(node_get(doc, "skills"), skill), doc), "mob %d has an unknown skill %q", mob.Id, skill)
+		}
+	}
+	for slot, item := range mob.Equipment {
+		if item != nil {
+			v.validate_item_data(path, node_get(node_get(doc, "equipment"), slot), item)
+		}
+	}
+	for i, item := range mob.Inventory {
+		if item != nil {
+			v.validate_item_data(path, node_index(node_get(doc, "inventory"), i), item)
+		}
+	}
+	for name, src := range mob.Progs {
+		v.validate_prog(path, node_line(node_get(node_get(doc, "progs"), name), doc), name, src)
+	}
+}
+
+func (v *validator) validate_area(path string) {
+	area := new(AreaData)
+	doc := v.validate_read(path, area)
+	if doc == nil {
+		return
+	}
+	line := node_line(node_get(doc, "name"), doc)
+	if area.Name == "" {
+		v.error(path, line, "area has no name")
+	} else if base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)); base != area.Name {
+		v.error(path, line, "area %s is in %s, it will be saved to %s.yml", area.Name, filepath.Base(path), area.Name)
+	}
+	for _, a := range v.areas {
+		if a.Name == area.Name {
+			v.error(path, line, "area %s is already defined in %s", area.Name, v.area_files[a])
+		}
+	}
+	if len(area.Levels) != 2 {
+		v.error(path, node_line(node_get(doc, "levels"), doc), "levels should be [min, max]")
+	}
+	if area.ResetInterval == 0 {
+		v.error(path, node_line(node_get(doc, "reset"), doc), "reset should be how many seconds between resets, not 0")
+	}
+	for _, f := range area.Flags {
+		if !is_room_flag(f) {
+			v.error(path, node_line(node_get(doc, "flags"), doc), "area %s has an unknown flag %q", area.Name, f)
+		}
+	}
+	if area.Sector != "" && !is_sector(area.Sector) {
+		v.error(path, node_line(node_get(doc, "sector"), doc), "area %s has an unknown sector %q", area.Name, area.Sector)
+	}
+	rooms := node_get(doc, "rooms")
+	for i := range area.Rooms {
+		room := &area.Rooms[i]
+		n := node_index(rooms, i)
+		rline := node_line(node_get(n, "id"), n)
+		if room.Id == 0 {
+			v.error(path, rline, "room has no id")
+		} else if loc, ok := v.rooms[room.Id]; ok {
+			v.error(path, rline, "room id %d is already used by %s", room.Id, loc)
+		} else {
+			v.rooms[room.Id] = validate_loc{path, rline}
+		}
+		for _, f := range room.Flags {
+			if !is_room_flag(f) {
+				v.error(path, node_line(node_get(n, "flags"), n), "room %d has an unknown flag %q", room.Id, f)
+			}
+		}
+		if room.Sector != "" && !is_sector(room.Sector) {
+			v.error(path, node_line(node_get(n, "sector"), n), "room %d has an unknown sector %q", room.Id, room.Sector)
+		}
+		for name, src := range room.RoomProgs {
+			v.validate_prog(path, node_line(node_get(node_get(n, "roomProgs"), name), n), name, src)
+		}
+	}
+	v.areas = append(v.areas, area)
+	v.area_files[area] = path
+	v.area_nodes[area] = doc
+}
+
+func (v *validator) validate_planet(path string) {
+	system := new(StarSystemData)
+	doc := v.validate_read(path, system)
+	if doc == nil {
+		return
+	}
+	orbits := node_get(doc, "orbits")
+	for k, o := range system.Orbits {
+		if _, ok := climate_list[o.Climate]; o.Climate != "" && !ok {
+			n := node_get(orbits, sprintf("%d", k))
+			v.error(path, node_line(node_get(n, "climate"), n, doc), "%s has an unknown climate %q", o.Name, o.Climate)
+		}
+	}
+	v.planets = append(v.planets, system)
+	v.planet_files[system] = path
+	v.planet_nodes[system] = doc
+}
+
+func (v *validator) validate_ship(path string) {
+	ship := new(ShipData)
+	doc := v.validate_read(path, ship)
+	if doc == nil {
+		return
+	}
+	line := node_line(node_get(doc, "name"), doc)
+	if ship.Name == "" {
+		v.error(path, line, "ship has no name")
+	}
+	rooms := node_get(doc, "rooms")
+	for id, room := range ship.Rooms {
+		if room == nil {
+			continue
+		}
+		n := node_get(rooms, sprintf("%d", id))
+		if room.Id != id {
+			v.error(path, node_line(node_get(n, "id"), n), "ship room %d has id %d", id, room.Id)
+		}
+		for dir, to := range room.Exits {
+			eline := node_line(node_get(node_get(n, "exits"), dir), n)
+			if !slice_contains_string(directions, dir) {
+				v.error(path, eline, "ship room %d has an exit in an unknown direction %q", id, dir)
+			}
+			if _, ok := ship.Rooms[to]; !ok {
+				v.error(path, eline, "ship room %d exit %s leads to room %d which isn't on the ship", id, dir, to)
+			}
+		}
+	}
+	for name, id := range map[string]uint{"cockpitRoom": ship.Cockpit, "rampRoom": ship.Ramp, "engineRoom": ship.EngineRoom, "cargoRoom": ship.CargoRoom} {
+		// fighters don't have an engine room or a hold.
+		if id == 0 && (name == "engineRoom" || name == "cargoRoom") {
+			continue
+		}
+		if _, ok := ship.Rooms[id]; !ok {
+			v.error(path, node_line(node_get(doc, name), doc), "%s %d isn't one of the ship's rooms", name, id)
+		}
+	}
+}
+
+func (v *validator) validate_help(path string) {
+	help := new(HelpData)
+	doc := v.validate_read(path, help)
+	if doc == nil {
+		return
+	}
+	if help.Name == "" {
+		v.error(path, node_line(doc), "help has no name")
+	}
+	if len(help.Keywords) == 0 {
+		v.error(path, node_line(node_get(doc, "keywords"), doc), "help %s has no keywords", help.Name)
+	}
+}
+
+// validate_reset_items checks that the items a reset gives out, and their contents, exist.
+func (v *validator) validate_reset_items(path string, n *yaml.Node, items []ResetItem, equip bool) {
+	for i, ri := range items {
+		rn := node_index(n, i)
+		line := node_line(node_get(rn, "item"), rn, n)
+		if _, ok := v.items[ri.Item]; !ok {
+			v.error(path, line, "reset item %d doesn't exist", ri.Item)
+		} else if equip {
+			if item := v.item_protos[ri.Item]; item != nil && item_wear_slot(item) == "" {
+				v.error(path, line, "reset item %d can't be equipped, use give", ri.Item)
+			}
+		}
+		if ri.Chance > 100 {
+			v.error(path, node_line(node_get(rn, "chance"), rn, n), "reset item %d has a chance over 100%%", ri.Item)
+		}
+		v.validate_reset_items(path, node_get(rn, "contents"), ri.Contents, false)
+	}
+}
+
+// validate_references checks everything that points at something else, once everything is loaded.
+func (v *validator) validate_references() {
+	for i, area := range v.areas {
+		path := v.area_files[area]
+		doc := v.area_nodes[area]
+		if err := area_vnum_conflict(area, v.areas[:i]); err != nil {
+			v.error(path, node_line(node_get(doc, "room_vnums"), node_get(doc, "name"), doc), "%v", err)
+		}
+		rooms := node_get(doc, "rooms")
+		coords := make(map[[3]int]uint)
+		for i := range area.Rooms {
+			room := &area.Rooms[i]
+			n := node_index(rooms, i)
+			if room.Coords != nil {
+				cline := node_line(node_get(n, "coords"), n)
+				if c, ok := room_coords(room); !ok {
+					v.error(path, cline, "room %d coords should be [x, y, z]", room.Id)
+				} else if other, ok := coords[c]; ok {
+					v.error(path, cline, "room %d is at the same coords as room %d", room.Id, other)
+				} else {
+					coords[c] = room.Id
+				}
+			}
+			exits := node_get(n, "exits")
+			for dir, to := range room.Exits {
+				eline := node_line(node_get(exits, dir), n)
+				if !slice_contains_string(directions, dir) {
+					v.error(path, eline, "room %d has an exit in an unknown direction %q", room.Id, dir)
+				}
+				if _, ok := v.rooms[to]; !ok {
+					v.error(path, eline, "room %d exit %s leads to room %d which doesn't exist", room.Id, dir, to)
+				}
+			}
+			flags := node_get(n, "exflags")
+			for dir, f := range room.ExitFlags {
+				fline := node_line(node_get(flags, dir), n)
+				if _, ok := room.Exits[dir]; !ok {
+					v.error(path, fline, "room %d has a door to the %s but no exit", room.Id, dir)
+				}
+				if f != nil && f.Key != 0 {
+					if _, ok := v.items[f.Key]; !ok {
+						v.error(path, fline, "room %d door %s needs key %d which doesn't exist", room.Id, dir, f.Key)
+					}
+				}
+				if f != nil && f.Difficulty > 100 {
+					v.error(path, fline, "room %d door %s has difficulty %d, it can't be more than 100", room.Id, dir, f.Difficulty)
+				}
+			}
+		}
+		for i, spawn := range area.Mobs {
+			n := node_index(node_get(doc, "mobs"), i)
+			if _, ok := v.mobs[spawn.Mob]; !ok {
+				v.error(path, node_line(node_get(n, "mob"), n), "mob spawn %d is for mob %d which doesn't exist", i, spawn.Mob)
+			}
+			// ship rooms only exist once the ship's in the game.
+			if _, ok := v.rooms[spawn.Room]; !ok && spawn.Ship == 0 {
+				v.error(path, node_line(node_get(n, "room"), n), "mob spawn %d is in room %d which doesn't exist", i, spawn.Room)
+			}
+			v.validate_reset_items(path, node_get(n, "equip"), spawn.Equip, true)
+			v.validate_reset_items(path, node_get(n, "give"), spawn.Give, false)
+		}
+		for i, spawn := range area.Items {
+			n := node_index(node_get(doc, "items"), i)
+			if _, ok := v.items[spawn.Item]; !ok {
+				v.error(path, node_line(node_get(n, "item"), n), "item spawn %d is for item %d which doesn't exist", i, spawn.Item)
+			}
+			if _, ok := v.rooms[spawn.Room]; !ok && spawn.Ship == 0 {
+				v.error(path, node_line(node_get(n, "room"), n), "item spawn %d is in room %d which doesn't exist", i, spawn.Room)
+			}
+			v.validate_reset_items(path, node_get(n, "contents"), spawn.Contents, false)
+		}
+		for i, door := range area.Doors {
+			n := node_index(node_get(doc, "doors"), i)
+			switch door.State {
+			case DOOR_OPEN, DOOR_CLOSED, DOOR_LOCKED:
+			default:
+				v.error(path, node_line(node_get(n, "state"), n), "door reset %d has an unknown state %q, use open, closed or locked", i, door.State)
+			}
+			if door.Ship > 0 {
+				continue
+			}
+			loc, ok := v.rooms[door.Room]
+			if !ok {
+				v.error(path, node_line(node_get(n, "room"), n), "door reset %d is in room %d which doesn't exist", i, door.Room)
+				continue
+			}
+			for _, a := range v.areas {
+				for _, r := range a.Rooms {
+					if r.Id == door.Room {
+						if _, ok := r.Exits[door.Direction]; !ok {
+							v.error(path, node_line(node_get(n, "dir"), n), "door reset %d is for exit %s of room %d (%s) which doesn't exist", i, door.Direction, door.Room, loc)
+						}
+					}
+				}
+			}
+		}
+	}
+	for _, system := range v.planets {
+		path := v.planet_files[system]
+		orbits := node_get(v.planet_nodes[system], "orbits")
+		for key, orbit := range system.Orbits {
+			n := node_get(orbits, sprintf("%d", key))
+			for i, port := range orbit.Spaceports {
+				if _, ok := v.rooms[uint(port)]; !ok {
+					v.error(path, node_line(node_index(node_get(n, "spaceports"), i), n), "%s spaceport %d doesn't exist", orbit.Name, port)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,300 @@
+/*  Star Wars Role-Playing Mud
+ *  Copyright (C) 2022 @{See Authors}
+ *
+ *  This program is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  This program is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *  GNU General Public License for more details.
+ *
+ *  You should have received a copy of the GNU General Public License
+ *  along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+package swr
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// PLANET_DAY is how many hours are in a day on a planet that doesn't say otherwise.
+const PLANET_DAY = 24
+
+// WeatherState is one kind of sky a climate can have.
+type WeatherState struct {
+	Name   string // what mud progs see, weather() == "sandstorm"
+	Look   string // what the sky looks like, shown with the room.
+	Worse  string // told to everyone outside when the weather turns to this.
+	Better string // told to everyone outside when the weather calms down to this.
+}
+
+// climate_list is the weather of each kind of planet (see [OribitalObject.Type] and [OribitalObject.Climate]), mildest first.
+// The weather only ever moves a step at a time, a clear day doesn't turn into a blizzard in an hour.
+var climate_list = map[string][]WeatherState{
+	"Desert": {
+		{Name: "clear", Look: "The sky is clear and the air shimmers with heat.", Better: "The wind dies down and the sand settles."},
+		{Name: "windy", Look: "A dry wind blows across the sand.", Worse: "A dry wind picks up.", Better: "The gusts ease off to a dry wind."},
+		{Name: "gusts", Look: "Gusts of wind whip sand into the air.", Worse: "Gusts of wind start whipping sand into the air.", Better: "The sandstorm blows itself out."},
+		{Name: "sandstorm", Look: "A sandstorm howls around you, sand stinging your skin.", Worse: "A wall of sand rolls in, a sandstorm is upon you!"},
+	},
+	"Ice": {
+		{Name: "clear", Look: "The sky is clear and the air bitterly cold.", Better: "The clouds break up, leaving a clear and bitterly cold sky."},
+		{Name: "overcast", Look: "Grey clouds hang low over the snow.", Worse: "Grey clouds roll in low over the snow.", Better: "The snow stops falling."},
+		{Name: "snowing", Look: "Snow falls steadily around you.", Worse: "Snow begins to fall.", Better: "The blizzard eases off to a steady snowfall."},
+		{Name: "blizzard", Look: "A blizzard rages, the driving snow all but blinding you.", Worse: "The wind howls as a blizzard sweeps in!"},
+	},
+	"Ocean": {
+		{Name: "clear", Look: "The sky is clear over the sea.", Better: "The clouds part and the sky clears."},
+		{Name: "overcast", Look: "Heavy clouds hang over the sea.", Worse: "Heavy clouds gather over the sea.", Better: "The rain stops."},
+		{Name: "rain", Look: "Rain pours down from a grey sky.", Worse: "It starts to rain.", Better: "The storm passes, leaving a steady rain."},
+		{Name: "storm", Look: "A storm lashes the sea, rain driving sideways in the wind.", Worse: "Lightning splits the sky as a storm rolls in off the sea!"},
+	},
+	"Rain": {
+		{Name: "rain", Look: "Rain pours down from a grey sky.", Better: "The downpour eases off to a steady rain."},
+		{Name: "downpour", Look: "Sheets of rain hammer down around you.", Worse: "The rain gets heavier, pouring down in sheets.", Better: "The storm passes, leaving a downpour."},
+		{Name: "storm", Look: "A storm lashes the sea, rain driving sideways in the wind.", Worse: "Lightning splits the sky as a storm rolls in off the sea!"},
+	},
+	"Swamp": {
+		{Name: "misty", Look: "A thin mist hangs over the swamp.", Better: "The fog thins out to a mist."},
+		{Name: "fog", Look: "A thick fog hides everything more than a few metres away.", Worse: "A thick fog creeps in over the swamp.", Better: "The rain stops, leaving the swamp shrouded in fog."},
+		{Name: "rain", Look: "A warm rain falls through the trees.", Worse: "A warm rain starts to fall.", Better: "The storm passes, leaving a warm rain."},
+		{Name: "storm", Look: "Thunder rumbles overhead as rain hammers the swamp.", Worse: "Thunder rumbles as a storm breaks over the swamp!"},
+	},
+	"Volcanic": {
+		{Name: "hazy", Look: "The sky glows red through a haze of smoke.", Better: "The ash stops falling."},
+		{Name: "ashfall", Look: "Ash drifts down from the sky.", Worse: "Ash begins to drift down from the sky.", Better: "The ash storm settles into a light ashfall."},
+		{Name: "ashstorm", Look: "Choking clouds of ash swirl around you.", Worse: "A rumble in the distance, and choking clouds of ash sweep in!"},
+	},
+	"City": {
+		{Name: "clear", Look: "Speeders stream across a clear sky.", Better: "The smog lifts."},
+		{Name: "smog", Look: "A layer of smog hangs between the towers.", Worse: "Smog settles in between the towers.", Better: "The rain stops."},
+		{Name: "rain", Look: "Rain streams down the sides of the towers.", Worse: "It starts to rain."},
+	},
+	"Gas": {
+		{Name: "clear", Look: "The clouds below glow in the sunlight.", Better: "The winds die down."},
+		{Name: "windy", Look: "Strong winds sweep through the cloud layer.", Worse: "The winds pick up.", Better: "The storm passes."},
+		{Name: "storm", Look: "Lightning flashes deep in the clouds as a storm rages.", Worse: "Lightning flashes in the clouds as a storm builds!"},
+	},
+	"Temperate": {
+		{Name: "clear", Look: "The sky is clear.", Better: "The clouds break up and the sky clears."},
+		{Name: "cloudy", Look: "Clouds drift across the sky.", Worse: "Clouds drift in across the sky.", Better: "The rain stops."},
+		{Name: "rain", Look: "A light rain is falling.", Worse: "It starts to rain.", Better: "The storm passes, leaving a light rain."},
+		{Name: "storm", Look: "Thunder rolls as a storm rages overhead.", Worse: "Thunder rolls as a storm breaks overhead!"},
+	},
+}
+
+// forests get the same weather as temperate planets, moons don't have any.
+func init() {
+	climate_list["Forest"] = climate_list["Temperate"]
+	climate_list["Moon"] = climate_list["Temperate"][:1]
+}
+
+// WeatherData is the sky over a planet.
+type WeatherData struct {
+	Planet  string // name of the orbital.
+	Day     int    // hours in the local day.
+	Suns    int    // how many stars the system has.
+	State   int    // index into the climate.
+	offset  int    // hours the local midnight is off standard midnight.
+	climate []WeatherState
+}
+
+var (
+	weather_lock    sync.Mutex
+	weather_planets map[string]*WeatherData // by lowercased planet name, and star system name.
+)
+
+// weather_key is how planet and area names are matched up, the MonCalamari area is on Mon Calamari.
+func weather_key(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, " ", ""))
+}
+
+// WeatherLoad sets up the weather on every planet, the time of day follows the galactic clock.
+func WeatherLoad() {
+	weather_lock.Lock()
+	weather_planets = make(map[string]*WeatherData)
+	systems := make([]*StarSystemData, 0)
+	for _, s := range DB().starsystems {
+		systems = append(systems, s.GetData())
+	}
+	for _, system := range systems {
+		keys := make([]int, 0)
+		for k := range system.Orbits {
+			keys = append(keys, k)
+		}
+		sort.Ints(keys)
+		for _, k := range keys {
+			o := system.Orbits[k]
+			name := o.Climate
+			if name == "" {
+				name = o.Type
+			}
+			climate, ok := climate_list[name]
+			if !ok {
+				climate = climate_list["Temperate"]
+			}
+			day := int(o.Day)
+			if day == 0 {
+				day = PLANET_DAY
+			}
+			w := &WeatherData{
+				Planet:  o.Name,
+				Day:     day,
+				Suns:    len(system.Stars),
+				State:   rand_min_max(0, len(climate)-1) / 2,
+				offset:  weather_offset(o.Name, day),
+				climate: climate,
+			}
+			weather_planets[weather_key(o.Name)] = w
+		}
+	}
+	// the star system is where its first planet is, unless a planet has the name.
+	for _, system := range systems {
+		key := weather_key(system.Name)
+		if _, ok := weather_planets[key]; ok {
+			continue
+		}
+		first := -1
+		for k := range system.Orbits {
+			if first == -1 || k < first {
+				first = k
+			}
+		}
+		if first != -1 {
+			weather_planets[key] = weather_planets[weather_key(system.Orbits[first].Name)]
+		}
+	}
+	weather_lock.Unlock()
+}
+
+// weather_offset spreads the planets' mornings around the clock, the same every boot.
+func weather_offset(name string, day int) int {
+	sum := 0
+	for _, c := range name {
+		sum += int(c)
+	}
+	return sum % day
+}
+
+// room_get_weather returns the weather on the planet the room is on, nil for ship rooms and areas that aren't on a planet.
+func room_get_weather(room *RoomData) *WeatherData {
+	if room == nil || room.ship > 0 || room.Area == nil {
+		return nil
+	}
+	weather_lock.Lock()
+	defer weather_lock.Unlock()
+	return weather_planets[weather_key(room.Area.Name)]
+}
+
+// room_is_outdoors is true if the room is open to the sky of a planet.
+func room_is_outdoors(room *RoomData) bool {
+	return room_get_weather(room) != nil && !room.IsFlagged(ROOM_FLAG_INDOORS)
+}
+
+// IsDay is true while the sun is up, the middle half of the day.
+func (w *WeatherData) IsDay() bool {
+	return w.is_day(w.GetHour())
+}
+
+// GetHour returns the hour of the local day, 0 is midnight.
+func (w *WeatherData) GetHour() int {
+	return w.hour_at(GetGameTime())
+}
+
+func (w *WeatherData) hour_at(t GameTime) int {
+	return int(floor_mod(int64(t)+int64(w.offset), int64(w.Day)))
+}
+
+// GetState returns what the sky is doing.
+func (w *WeatherData) GetState() WeatherState {
+	weather_lock.Lock()
+	defer weather_lock.Unlock()
+	return w.climate[w.State]
+}
+
+func (w *WeatherData) is_day(hour int) bool {
+	return hour >= w.Day/4 && hour < w.Day*3/4
+}
+
+// suns is what the locals call their sun, and the verb ending to go with it.
+func (w *WeatherData) suns() (string, string) {
+	switch w.Suns {
+	case 0:
+		return "distant stars", ""
+	case 1:
+		return "sun", "s"
+	case 2:
+		return "twin suns", ""
+	}
+	return "suns", ""
+}
+
+// Look is the sky as seen from the room, the time of day and the weather.
+func (w *WeatherData) Look() string {
+	weather_lock.Lock()
+	defer weather_lock.Unlock()
+	sun, s := w.suns()
+	hour := w.GetHour()
+	sky := "It is night."
+	switch {
+	case hour == w.Day/4:
+		sky = sprintf("The %s rise%s over the horizon.", sun, s)
+	case hour == w.Day*3/4-1:
+		sky = sprintf("The %s hang%s low in the sky.", sun, s)
+	case w.is_day(hour):
+		sky = "It is day."
+	}
+	return sky + " " + w.climate[w.State].Look
+}
+
+// weather_update is run every hour, the sun comes up or goes down and maybe the weather changes. Everyone outside is told.
+func weather_update() {
+	messages := make(map[*WeatherData][]string)
+	now := GetGameTime()
+	weather_lock.Lock()
+	for key, w := range weather_planets {
+		if key != weather_key(w.Planet) {
+			continue
+		}
+		day := w.is_day(w.hour_at(now - 1))
+		sun, s := w.suns()
+		if !day && w.is_day(w.hour_at(now)) {
+			messages[w] = append(messages[w], sprintf("&YThe %s rise%s over the horizon.&d", sun, s))
+		} else if day && !w.is_day(w.hour_at(now)) {
+			messages[w] = append(messages[w], sprintf("&BThe %s set%s and night falls.&d", sun, s))
+		}
+		if len(w.climate) < 2 || rand_min_max(1, 4) != 1 {
+			continue
+		}
+		// storms don't last, the weather leans towards the mild end.
+		if w.State > 0 && rand_min_max(1, 100) <= 60 {
+			w.State--
+			messages[w] = append(messages[w], sprintf("&c%s&d", w.climate[w.State].Better))
+		} else if w.State < len(w.climate)-1 {
+			w.State++
+			messages[w] = append(messages[w], sprintf("&c%s&d", w.climate[w.State].Worse))
+		}
+	}
+	weather_lock.Unlock()
+	if len(messages) == 0 {
+		return
+	}
+	for _, e := range DB().GetPlayerEntities() {
+		if entity_unspeakable_state(e) {
+			continue
+		}
+		room := DB().GetRoom(e.RoomId(), e.ShipId())
+		if room == nil || !room_is_outdoors(room) {
+			continue
+		}
+		for _, m := range messages[room_get_weather(room)] {
+			e.Send("\r\n%s\r\n", m)
+		}
+	}
+}
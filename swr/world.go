@@ -0,0 +1,215 @@
+/*  Star Wars Role-Playing Mud
+ *  Copyright (C) 2022 @{See Authors}
+ *
+ *  This program is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  This program is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *  GNU General Public License for more details.
+ *
+ *  You should have received a copy of the GNU General Public License
+ *  along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+package swr
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WorldState is everything lying around the game world that isn't part of an area prototype, and the galactic clock.
+// It's saved to data/world.yml and loaded after the areas (and ships) but before the areas reset.
+type WorldState struct {
+	Saved time.Time   `yaml:"saved"`
+	Time  GameTime    `yaml:"time"` // the galactic clock, see [GetGameTime].
+	Rooms []WorldRoom `yaml:"rooms"`
+}
+
+// WorldRoom is the contents of a room instance. Ship is 0 for planet-side rooms.
+type WorldRoom struct {
+	Room  uint        `yaml:"room"`
+	Ship  uint        `yaml:"ship,omitempty"`
+	Items []WorldItem `yaml:"items"`
+}
+
+// WorldItem is an item instance, along with the contents if it's a container.
+type WorldItem struct {
+	Item     *ItemData   `yaml:"item"`
+	Contents []WorldItem `yaml:"contents,omitempty"`
+}
+
+const world_state_file = "data/world.yml"
+
+func world_item_state(item Item) WorldItem {
+	data := *item.GetData()
+	data.Items = nil
+	w := WorldItem{Item: &data, Contents: make([]WorldItem, 0)}
+	for _, i := range item.GetData().Items {
+		if i == nil {
+			continue
+		}
+		w.Contents = append(w.Contents, world_item_state(i))
+	}
+	return w
+}
+
+func world_item_restore(d *GameDatabase, w WorldItem) Item {
+	item := w.Item
+	item.Items = make([]Item, 0)
+	if proto, ok := d.items[item.GetTypeId()]; ok {
+		item.Filename = proto.Filename
+	}
+	for _, c := range w.Contents {
+		if c.Item == nil {
+			continue
+		}
+		item.Items = append(item.Items, world_item_restore(d, c))
+	}
+	return item
+}
+
+func world_room_state(room *RoomData, ship uint) *WorldRoom {
+	if len(room.Items) == 0 {
+		return nil
+	}
+	w := &WorldRoom{Room: room.Id, Ship: ship, Items: make([]WorldItem, 0)}
+	for _, i := range room.Items {
+		if i == nil {
+			continue
+		}
+		w.Items = append(w.Items, world_item_state(i))
+	}
+	if len(w.Items) == 0 {
+		return nil
+	}
+	return w
+}
+
+// SaveWorld writes the contents of every planet-side room to data/world.yml. Expects the database to be locked.
+func (d *GameDatabase) SaveWorld() error {
+	state := WorldState{Saved: time.Now().UTC(), Time: GetGameTime(), Rooms: make([]WorldRoom, 0)}
+	for _, room := range d.rooms {
+		if room == nil {
+			continue
+		}
+		if w := world_room_state(room, 0); w != nil {
+			state.Rooms = append(state.Rooms, *w)
+		}
+	}
+	err := write_yaml(world_state_file, state)
+	ErrorCheck(err)
+	return err
+}
+
+// LoadWorld puts the saved room contents back into the world, sets the clock and starts the junk decay timer.
+func (d *GameDatabase) LoadWorld() {
+	ScheduleFunc(world_decay, true, 60)
+	if !file_exists(world_state_file) {
+		return
+	}
+	log.Printf("Loading world state.")
+	fp, err := os.ReadFile(world_state_file)
+	ErrorCheck(err)
+	state := new(WorldState)
+	err = yaml.Unmarshal(fp, state)
+	ErrorCheck(err)
+	if err != nil {
+		return
+	}
+	SetGameTime(state.Time)
+	log.Printf("It's %s.", state.Time)
+	count := 0
+	for _, w := range state.Rooms {
+		room := d.GetRoom(w.Room, w.Ship)
+		if room == nil {
+			log.Printf("Error: roomId %d (ship %d) doesn't exist! LoadWorld()", w.Room, w.Ship)
+			continue
+		}
+		if w.Ship != 0 && len(room.Items) > 0 {
+			// ships save what's inside them with the ship now, see [GameDatabase.SaveShip].
+			// older saves kept them here, those are only moved aboard if the ship didn't bring its own.
+			log.Printf("Skipping %d items saved for ship %d room %d, the ship has its own. LoadWorld()", len(w.Items), w.Ship, w.Room)
+			continue
+		}
+		d.Lock()
+		for _, i := range w.Items {
+			if i.Item == nil {
+				continue
+			}
+			room.AddItem(world_item_restore(d, i))
+			count++
+		}
+		d.Unlock()
+	}
+	log.Printf("%d items restored to %d rooms.", count, len(state.Rooms))
+}
+
+// item_set_decay starts the decay timer on an item left lying around.
+func item_set_decay(item Item) {
+	seconds := Config().Decay.Junk
+	if item.IsCorpse() {
+		seconds = Config().Decay.Corpse
+	}
+	if seconds == 0 {
+		item.GetData().Decay = time.Time{}
+		return
+	}
+	item.GetData().Decay = time.Now().UTC().Add(time.Duration(seconds) * time.Second)
+}
+
+// item_clear_decay stops the decay timer, for when an item is picked up.
+func item_clear_decay(item Item) {
+	item.GetData().Decay = time.Time{}
+}
+
+// world_decay removes any junk or corpses whose decay timers have run out.
+func world_decay() {
+	db := DB()
+	now := time.Now().UTC()
+	rooms := make([]*RoomData, 0)
+	db.Lock()
+	for _, room := range db.rooms {
+		if room != nil {
+			rooms = append(rooms, room)
+		}
+	}
+	for _, ship := range db.ships {
+		if ship == nil {
+			continue
+		}
+		for _, room := range ship.GetData().Rooms {
+			if room != nil {
+				rooms = append(rooms, room)
+			}
+		}
+	}
+	db.Unlock()
+	for _, room := range rooms {
+		decayed := make([]Item, 0)
+		for _, i := range room.Items {
+			if i == nil {
+				continue
+			}
+			decay := i.GetData().Decay
+			if !decay.IsZero() && decay.Before(now) {
+				decayed = append(decayed, i)
+			}
+		}
+		for _, i := range decayed {
+			room.RemoveItem(i)
+			if i.IsCorpse() {
+				room.SendToRoom(sprintf("\r\n&d%s rots away to nothing.\r\n", capitalize(i.GetData().Name)))
+			} else {
+				room.SendToRoom(sprintf("\r\n&d%s crumbles into dust.\r\n", capitalize(i.GetData().Name)))
+			}
+		}
+	}
+}